@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	ecrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+var defaultMnemonic = "test test test test test test test test test test test junk"
+
+// defaultDerivationPath is the BIP-44 path of the Ethereum accounts, the index
+// of the account is appended to it.
+var defaultDerivationPath = "m/44'/60'/0'/0"
+
+// hardenedOffset is the first index of the hardened BIP-32 child keys
+const hardenedOffset = 0x80000000
+
+// prefundedAccount is an account funded in the genesis block
+type prefundedAccount struct {
+	priv *ecdsa.PrivateKey
+}
+
+func (p *prefundedAccount) PrivKeyHex() string {
+	return "0x" + fmt.Sprintf("%064x", p.priv.D)
+}
+
+func (p *prefundedAccount) Address() string {
+	return ecrypto.PubkeyToAddress(p.priv.PublicKey).Hex()
+}
+
+// deriveAccounts derives the first num accounts of the mnemonic under the BIP-44 path
+func deriveAccounts(mnemonic string, path string, num uint64) ([]*prefundedAccount, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(strings.Join(strings.Fields(mnemonic), " "), "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %v", err)
+	}
+	indexes, err := parseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// master key
+	key, chainCode := hmacSHA512([]byte("Bitcoin seed"), seed)
+	for _, index := range indexes {
+		if key, chainCode, err = deriveChildKey(key, chainCode, index); err != nil {
+			return nil, err
+		}
+	}
+
+	accounts := make([]*prefundedAccount, 0, num)
+	for i := uint64(0); i < num; i++ {
+		if i >= hardenedOffset {
+			return nil, fmt.Errorf("too many accounts: %d", num)
+		}
+		childKey, _, err := deriveChildKey(key, chainCode, uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		priv, err := ecrypto.ToECDSA(childKey)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, &prefundedAccount{priv: priv})
+	}
+	return accounts, nil
+}
+
+// parseDerivationPath parses a BIP-32 path like m/44'/60'/0'/0
+func parseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path '%s', it must start with 'm'", path)
+	}
+
+	indexes := []uint32{}
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+		index, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path '%s': %v", path, err)
+		}
+		if hardened {
+			index += hardenedOffset
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// deriveChildKey derives the private child key at index following BIP-32
+func deriveChildKey(key, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	data := []byte{}
+	if index >= hardenedOffset {
+		data = append(data, 0x0)
+		data = append(data, key...)
+	} else {
+		priv, err := ecrypto.ToECDSA(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = append(data, ecrypto.CompressPubkey(&priv.PublicKey)...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	il, childChainCode := hmacSHA512(chainCode, data)
+
+	curveOrder := ecrypto.S256().Params().N
+	ilNum := new(big.Int).SetBytes(il)
+	if ilNum.Cmp(curveOrder) >= 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	childNum := ilNum.Add(ilNum, new(big.Int).SetBytes(key))
+	childNum.Mod(childNum, curveOrder)
+	if childNum.Sign() == 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+
+	childKey := make([]byte, 32)
+	childNum.FillBytes(childKey)
+	return childKey, childChainCode, nil
+}
+
+func hmacSHA512(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDeriveAccountsDefaultMnemonic(t *testing.T) {
+	// the well-known accounts of anvil and hardhat for the default mnemonic
+	expected := []struct {
+		address string
+		privKey string
+	}{
+		{"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"},
+		{"0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"},
+		{"0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC", "0x5de4111afa1a4b94908f83103eb1f1706367c2e68ca870fc3fb9a804cdab365a"},
+		{"0x90F79bf6EB2c4f870365E785982E1f101E93b906", "0x7c852118294e51e653712a81e05800f419141751be58f605c371e15141b007a6"},
+		{"0x15d34AAf54267DB7D7c367839AAf71A00a2C6A65", "0x47e179ec197488593b187f80a00eb0da91f1b9d0b13f8733639f19c30a34926a"},
+		{"0x9965507D1a55bcC2695C58ba16FB37d819B0A4dc", "0x8b3a350cf5c34c9194ca85829a2df0ec3153be0318b5e2d3348e872092edffba"},
+		{"0x976EA74026E726554dB657fA54763abd0C3a0aa9", "0x92db14e403b83dfe3df233f83dfa3a0d7096f21ca9b0d6d6b8d88b2b4ec1564e"},
+		{"0x14dC79964da2C08b23698B3D3cc7Ca32193d9955", "0x4bbbf85ce3377467afe5d46f804f221813b2bb87f24d81f60f1fcdbf7cbf4356"},
+		{"0x23618e81E3f5cdF7f54C3d65f7FBc0aBf5B21E8f", "0xdbda1821b80551c9d65939329250298aa3472ba22feea921c0cf5d620ea67b97"},
+		{"0xa0Ee7A142d267C1f36714E4a8F75612F20a79720", "0x2a871d0798f97d79848a013d4936a73bf4cc922c825d33c1cf7073dff6d409c6"},
+	}
+
+	accounts, err := deriveAccounts(defaultMnemonic, defaultDerivationPath, uint64(len(expected)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != len(expected) {
+		t.Fatalf("expected %d accounts but got %d", len(expected), len(accounts))
+	}
+	for i, account := range accounts {
+		if account.Address() != expected[i].address {
+			t.Errorf("account %d: expected address %s but got %s", i, expected[i].address, account.Address())
+		}
+		if account.PrivKeyHex() != expected[i].privKey {
+			t.Errorf("account %d: expected private key %s but got %s", i, expected[i].privKey, account.PrivKeyHex())
+		}
+	}
+}
+
+func TestDeriveChildKey(t *testing.T) {
+	// test vector 1 of BIP-32, chain m/0H/1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	key, chainCode := hmacSHA512([]byte("Bitcoin seed"), seed)
+	if got := hex.EncodeToString(key); got != "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35" {
+		t.Fatalf("unexpected master key %s", got)
+	}
+
+	expected := []struct {
+		index uint32
+		key   string
+	}{
+		{hardenedOffset, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{1, "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+	}
+	for _, e := range expected {
+		var err error
+		if key, chainCode, err = deriveChildKey(key, chainCode, e.index); err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key); got != e.key {
+			t.Fatalf("index %d: expected key %s but got %s", e.index, e.key, got)
+		}
+	}
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	indexes, err := parseDerivationPath("m/44'/60'/0h/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []uint32{hardenedOffset + 44, hardenedOffset + 60, hardenedOffset, 0}
+	if len(indexes) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, indexes)
+	}
+	for i := range expected {
+		if indexes[i] != expected[i] {
+			t.Fatalf("expected %v but got %v", expected, indexes)
+		}
+	}
+
+	for _, path := range []string{"44'/60'", "m/a", "m/2147483648"} {
+		if _, err := parseDerivationPath(path); err == nil {
+			t.Errorf("expected an error for %s", path)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package artifacts
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+var elfMachines = map[string]elf.Machine{
+	"amd64": elf.EM_X86_64,
+	"arm64": elf.EM_AARCH64,
+}
+
+var machoCpus = map[string]macho.Cpu{
+	"amd64": macho.CpuAmd64,
+	"arm64": macho.CpuArm64,
+}
+
+// checkBinaryArch checks that the binary can be executed in the host os and arch
+func checkBinaryArch(path string) error {
+	return checkBinaryArchFor(path, runtime.GOOS, runtime.GOARCH)
+}
+
+func checkBinaryArchFor(path string, goos, goarch string) error {
+	header := make([]byte, 4)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	_, err = f.Read(header)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("error reading binary: %v", err)
+	}
+
+	if bytes.HasPrefix(header, []byte("#!")) {
+		// scripts are run by the interpreter of the host
+		return nil
+	}
+
+	switch goos {
+	case "linux":
+		expected, ok := elfMachines[goarch]
+		if !ok {
+			return fmt.Errorf("unsupported arch: %s/%s", goos, goarch)
+		}
+		bin, err := elf.Open(path)
+		if err != nil {
+			return fmt.Errorf("not an ELF binary: %v", err)
+		}
+		defer bin.Close()
+
+		if bin.Machine != expected {
+			return fmt.Errorf("binary is built for %s but the host is %s/%s", bin.Machine, goos, goarch)
+		}
+		return nil
+
+	case "darwin":
+		expected, ok := machoCpus[goarch]
+		if !ok {
+			return fmt.Errorf("unsupported arch: %s/%s", goos, goarch)
+		}
+
+		var cpus []macho.Cpu
+		if fat, err := macho.OpenFat(path); err == nil {
+			for _, arch := range fat.Arches {
+				cpus = append(cpus, arch.Cpu)
+			}
+			fat.Close()
+		} else if bin, err := macho.Open(path); err == nil {
+			cpus = append(cpus, bin.Cpu)
+			bin.Close()
+		} else {
+			return fmt.Errorf("not a Mach-O binary: %v", err)
+		}
+
+		for _, cpu := range cpus {
+			// Apple silicon runs x86_64 binaries with Rosetta
+			if cpu == expected || (goarch == "arm64" && cpu == macho.CpuAmd64) {
+				return nil
+			}
+		}
+		return fmt.Errorf("binary is built for %v but the host is %s/%s", cpus, goos, goarch)
+	}
+
+	return fmt.Errorf("unsupported os: %s", goos)
+}
@@ -1,15 +1,18 @@
 package artifacts
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v2"
 )
 
 type release struct {
@@ -17,71 +20,218 @@ type release struct {
 	Org     string
 	Version string
 	Arch    func(string, string) string
+
+	// Archive returns the file name of the release asset for a version and arch.
+	// Defaults to <name>-<version>-<arch>.tar.gz. The format of the asset (tar.gz,
+	// tar.xz, zip or a raw binary) is detected when it is extracted.
+	Archive func(string, string) string
+
+	// Binary is the name of the binary inside the release archive, it can be
+	// nested in any directory. Defaults to the name of the release.
+	Binary string
+
+	// SigningKeys are the fingerprints of the keys of the maintainers that sign the release archives
+	SigningKeys []string
+
+	// Checksums are the expected sha256 digests of the release archives of the default
+	// version indexed by arch. Archives without a pinned digest are looked up in the
+	// checksums manifest.
+	Checksums map[string]string
 }
 
-func DownloadArtifacts() (map[string]string, error) {
-	var artifacts = []release{
-		{
-			Name:    "reth",
-			Org:     "paradigmxyz",
-			Version: "v1.0.2",
-			Arch: func(goos, goarch string) string {
-				if goos == "linux" {
-					return "x86_64-unknown-linux-gnu"
-				} else if goos == "darwin" && goarch == "arm64" { // Apple M1
-					return "aarch64-apple-darwin"
-				} else if goos == "darwin" && goarch == "amd64" {
-					return "x86_64-apple-darwin"
-				}
-				return ""
-			},
+// checksumsManifest is the name of the file under the playground home directory
+// with the sha256 digests of the release archives in the format of sha256sum.
+const checksumsManifest = "checksums.txt"
+
+// Config is the configuration of DownloadArtifacts
+type Config struct {
+	// Versions overrides the default version of the clients, indexed by client name
+	Versions map[string]string `yaml:"versions"`
+
+	// LockFile is the path of the lockfile with the resolved versions and binary digests.
+	// If the lockfile exists, its versions are used instead of the configured ones.
+	LockFile string `yaml:"-"`
+
+	// Upgrade resolves the versions again and overwrites the ones in the lockfile
+	Upgrade bool `yaml:"-"`
+
+	// Source is where the binaries are fetched from (see NewSource). Defaults to
+	// the GitHub releases page of each client.
+	Source string `yaml:"source"`
+
+	// VerifySignatures refuses the release archives without a valid signature
+	// from one of the pinned maintainer keys of the client
+	VerifySignatures bool `yaml:"verify_signatures"`
+
+	// SigningKeyring is an optional file with the armored public keys of the maintainers.
+	// Pinned keys not found in it are taken from the keys embedded in the playground.
+	SigningKeyring string `yaml:"signing_keyring"`
+
+	// CacheDir is the directory where the binaries are cached. Defaults to the
+	// value of the PLAYGROUND_HOME environment variable or $HOME/.playground.
+	CacheDir string `yaml:"cache_dir"`
+
+	// AllowUnverified accepts release archives that cannot be verified, either
+	// against a known digest, a signature or the lockfile
+	AllowUnverified bool `yaml:"allow_unverified"`
+}
+
+// CacheDirEnv is the environment variable that overrides the default cache directory
+const CacheDirEnv = "PLAYGROUND_HOME"
+
+// ReadConfig reads the artifacts configuration from a YAML file
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error decoding config %s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+var releases = []release{
+	{
+		Name:    "reth",
+		Org:     "paradigmxyz",
+		Version: "v1.0.2",
+		SigningKeys: []string{
+			"A3AE097C89093A124049DF1F5391A3C4100530B4",
 		},
-		{
-			Name:    "lighthouse",
-			Org:     "sigp",
-			Version: "v5.2.1",
-			Arch: func(goos, goarch string) string {
-				if goos == "linux" {
-					return "x86_64-unknown-linux-gnu"
-				} else if goos == "darwin" && goarch == "arm64" { // Apple M1
-					return "x86_64-apple-darwin-portable"
-				} else if goos == "darwin" && goarch == "amd64" {
-					return "x86_64-apple-darwin"
-				}
-				return ""
-			},
+		Arch: func(goos, goarch string) string {
+			if goos == "linux" && goarch == "amd64" {
+				return "x86_64-unknown-linux-gnu"
+			} else if goos == "linux" && goarch == "arm64" {
+				return "aarch64-unknown-linux-gnu"
+			} else if goos == "darwin" && goarch == "arm64" { // Apple M1
+				return "aarch64-apple-darwin"
+			} else if goos == "darwin" && goarch == "amd64" {
+				return "x86_64-apple-darwin"
+			}
+			return ""
+		},
+	},
+	{
+		Name:    "lighthouse",
+		Org:     "sigp",
+		Version: "v5.2.1",
+		SigningKeys: []string{
+			"15E66D941F697E28F49381F426416DC3F30674B0",
 		},
+		Arch: func(goos, goarch string) string {
+			if goos == "linux" && goarch == "amd64" {
+				return "x86_64-unknown-linux-gnu"
+			} else if goos == "linux" && goarch == "arm64" {
+				return "aarch64-unknown-linux-gnu"
+			} else if goos == "darwin" && goarch == "arm64" { // Apple M1
+				return "x86_64-apple-darwin-portable"
+			} else if goos == "darwin" && goarch == "amd64" {
+				return "x86_64-apple-darwin"
+			}
+			return ""
+		},
+	},
+}
+
+// resolveVersions returns the version of each client given the config and the lockfile.
+// The version in the lockfile takes precedence unless it is explicitly upgraded.
+func resolveVersions(cfg *Config) (map[string]string, lockFile, error) {
+	for name := range cfg.Versions {
+		if findRelease(name) == nil {
+			return nil, nil, fmt.Errorf("unknown client in versions: %s", name)
+		}
 	}
 
-	homeDir, err := os.UserHomeDir()
+	lock, err := readLockFile(cfg.LockFile)
 	if err != nil {
-		return nil, fmt.Errorf("error getting user home directory: %w", err)
+		return nil, nil, fmt.Errorf("error reading lockfile: %v", err)
+	}
+	if cfg.Upgrade {
+		lock = lockFile{}
 	}
 
-	// Define the path for our custom home directory
-	customHomeDir := filepath.Join(homeDir, ".playground")
+	versions := map[string]string{}
+	for _, artifact := range releases {
+		version := artifact.Version
+		if v, ok := cfg.Versions[artifact.Name]; ok {
+			version = v
+		}
+		if locked, ok := lock[artifact.Name]; ok {
+			if _, ok := cfg.Versions[artifact.Name]; ok && version != locked.Version {
+				return nil, nil, fmt.Errorf("%s version %s does not match version %s in the lockfile, use --upgrade to update it", artifact.Name, version, locked.Version)
+			}
+			version = locked.Version
+		}
+		versions[artifact.Name] = version
+	}
+	return versions, lock, nil
+}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(customHomeDir, 0755); err != nil {
-		return nil, fmt.Errorf("error creating output directory: %v", err)
+func DownloadArtifacts(cfg *Config) (map[string]string, error) {
+	source, err := NewSource(cfg.Source)
+	if err != nil {
+		return nil, err
 	}
 
+	versions, lock, err := resolveVersions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	customHomeDir, err := cacheDir(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// hold the lock of the cache while it is populated so that concurrent
+	// playgrounds do not write the same binaries at the same time
+	unlock, err := lockCacheDir(customHomeDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	goos := runtime.GOOS
 	goarch := runtime.GOARCH
 
 	fmt.Printf("Architecture detected: %s/%s\n", goos, goarch)
 
+	manifest, err := readChecksumsManifest(filepath.Join(customHomeDir, checksumsManifest))
+	if err != nil {
+		return nil, fmt.Errorf("error reading checksums manifest: %v", err)
+	}
+
 	// Try to download the release binaries for 'reth' and 'lighthouse'. It works as follows:
-	// 1. Check under $HOME/.playground if the binary-<version> exists. If exists, use it.
-	// 2. If the binary does not exists, use the arch and os to download the binary from the release page.
+	// 1. Check under the cache directory if the binary-<version> exists and its digest matches
+	// the one recorded at download time. If so, use it. Otherwise, download it again.
+	// 2. If the binary does not exists, use the arch and os to fetch the binary from the source.
 	// 3. If the architecture is not supported, check if the binary is found in PATH.
-	releases := make(map[string]string)
-	for _, artifact := range artifacts {
-		outPath := filepath.Join(customHomeDir, artifact.Name+"-"+artifact.Version)
+	binaries := make(map[string]string)
+	newLock := lockFile{}
+	for _, artifact := range releases {
+		version := versions[artifact.Name]
+		locked := lock[artifact.Name]
+		lockedDigest := locked.SHA256[platform(goos, goarch)]
+
+		outPath := filepath.Join(customHomeDir, artifact.Name+"-"+version)
 		_, err := os.Stat(outPath)
 		if err != nil && !os.IsNotExist(err) {
 			return nil, fmt.Errorf("error checking file existence: %v", err)
 		}
+		if err == nil {
+			if _, err = verifyBinary(outPath, lockedDigest); err == nil && cfg.VerifySignatures {
+				// binaries cached without verifying the signature of their archive are not trusted
+				err = verifySigned(outPath, artifact.SigningKeys)
+			}
+			if err != nil {
+				fmt.Printf("%s failed verification (%v), downloading it again\n", outPath, err)
+				if err := removeBinary(outPath); err != nil {
+					return nil, err
+				}
+			}
+		}
 
 		if err != nil {
 			archVersion := artifact.Arch(goos, goarch)
@@ -89,85 +239,345 @@ func DownloadArtifacts() (map[string]string, error) {
 				// Case 2. The architecture is not supported.
 				fmt.Printf("unsupported OS/Arch: %s/%s\n", goos, goarch)
 				if _, err := exec.LookPath(artifact.Name); err != nil {
-					return nil, fmt.Errorf("error looking up binary in PATH: %v", err)
+					return nil, fmt.Errorf("%s has no release for %s/%s and it is not found in PATH: %v", artifact.Name, goos, goarch, err)
 				} else {
 					outPath = artifact.Name
 					fmt.Printf("Using %s from PATH\n", artifact.Name)
 				}
 			} else {
-				// Case 3. Download the binary from the release page
-				releasesURL := fmt.Sprintf("https://github.com/%s/%s/releases/download/%s/%s-%s-%s.tar.gz", artifact.Org, artifact.Name, artifact.Version, artifact.Name, artifact.Version, archVersion)
-				fmt.Printf("Downloading %s: %s\n", outPath, releasesURL)
+				// Case 3. Fetch the binary from the source
+				asset := &Asset{
+					Name:    artifact.Name,
+					Org:     artifact.Org,
+					Version: version,
+					Arch:    archVersion,
+					Archive: fmt.Sprintf("%s-%s-%s.tar.gz", artifact.Name, version, archVersion),
+					Binary:  artifact.Name,
+				}
+				if artifact.Archive != nil {
+					asset.Archive = artifact.Archive(version, archVersion)
+				}
+				if artifact.Binary != "" {
+					asset.Binary = artifact.Binary
+				}
+				if version == artifact.Version {
+					asset.Checksum = artifact.Checksums[archVersion]
+				}
+				if asset.Checksum == "" {
+					asset.Checksum = manifest[asset.Archive]
+				}
+				// a binary pinned in the lockfile is verified once it is extracted
+				asset.AllowUnverified = cfg.AllowUnverified || lockedDigest != ""
+				if cfg.VerifySignatures {
+					if len(artifact.SigningKeys) == 0 {
+						return nil, fmt.Errorf("no signing keys pinned for %s", artifact.Name)
+					}
+					if asset.Keyring, err = loadSigningKeys(artifact.SigningKeys, cfg.SigningKeyring); err != nil {
+						return nil, fmt.Errorf("error loading signing keys of %s: %v", artifact.Name, err)
+					}
+				}
 
-				if err := downloadArtifact(releasesURL, artifact.Name, outPath); err != nil {
-					return nil, fmt.Errorf("error downloading artifact: %v", err)
+				if err := source.Fetch(asset, outPath); err != nil {
+					// do not leave a partial or unverified binary in the cache
+					removeBinary(outPath)
+					return nil, fmt.Errorf("error fetching artifact: %v", err)
+				}
+				if _, err := verifyBinary(outPath, lockedDigest); err != nil {
+					removeBinary(outPath)
+					return nil, fmt.Errorf("%s does not match the lockfile: %v", outPath, err)
 				}
 			}
 		} else {
-			// Case 1. Use the binary in $HOME/.playground
+			// Case 1. Use the binary in the cache directory
 			fmt.Printf("%s already exists, skipping download\n", outPath)
 		}
 
-		releases[artifact.Name] = outPath
+		// keep the digests of the other platforms so that the same lockfile works everywhere
+		entry := lockEntry{Version: version, SHA256: map[string]string{}}
+		if locked.Version == version {
+			for p, digest := range locked.SHA256 {
+				entry.SHA256[p] = digest
+			}
+		}
+		if outPath != artifact.Name {
+			// binaries from the PATH are not pinned in the lockfile
+			digest, err := verifyBinary(outPath, "")
+			if err != nil {
+				return nil, err
+			}
+			entry.SHA256[platform(goos, goarch)] = digest
+		}
+		if len(entry.SHA256) != 0 {
+			newLock[artifact.Name] = entry
+		}
+		binaries[artifact.Name] = outPath
 	}
 
-	return releases, nil
+	if err := writeLockFile(cfg.LockFile, newLock); err != nil {
+		return nil, fmt.Errorf("error writing lockfile: %v", err)
+	}
+	return binaries, nil
 }
 
-func downloadArtifact(url string, expectedFile string, outPath string) error {
-	// Download the file
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("error downloading file: %v", err)
+// cacheDir returns the directory where the binaries are cached and creates it if it does not exist
+func cacheDir(cfg *Config) (string, error) {
+	customHomeDir := cfg.CacheDir
+	if customHomeDir == "" {
+		customHomeDir = os.Getenv(CacheDirEnv)
+	}
+	if customHomeDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting user home directory: %w", err)
+		}
+
+		// Define the path for our custom home directory
+		customHomeDir = filepath.Join(homeDir, ".playground")
+	}
+
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(customHomeDir, 0755); err != nil {
+		return "", fmt.Errorf("error creating output directory: %v", err)
 	}
-	defer resp.Body.Close()
+	return customHomeDir, nil
+}
+
+// CachedBinary is a binary of a client stored in the cache directory
+type CachedBinary struct {
+	Name    string
+	Version string
+	Path    string
+	Size    int64
 
-	// Create a gzip reader
-	gzipReader, err := gzip.NewReader(resp.Body)
+	// SHA256 is the digest recorded when the binary was cached
+	SHA256 string
+
+	// InUse is set if the binary is the one resolved by the current configuration
+	InUse bool
+}
+
+// listCachedBinaries returns the binaries in the cache directory named <name>-<version>
+func listCachedBinaries(dir string) ([]*CachedBinary, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return fmt.Errorf("error creating gzip reader: %v", err)
+		return nil, err
+	}
+
+	binaries := []*CachedBinary{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		fileName := entry.Name()
+		if ext := filepath.Ext(fileName); isCacheTempFile(fileName) || ext == ".sha256" || ext == ".signed" {
+			continue
+		}
+		for _, r := range releases {
+			if version, ok := strings.CutPrefix(fileName, r.Name+"-"); ok {
+				info, err := entry.Info()
+				if err != nil {
+					return nil, err
+				}
+				bin := &CachedBinary{
+					Name:    r.Name,
+					Version: version,
+					Path:    filepath.Join(dir, fileName),
+					Size:    info.Size(),
+				}
+				if digest, err := os.ReadFile(bin.Path + ".sha256"); err == nil {
+					bin.SHA256 = strings.TrimSpace(string(digest))
+				}
+				binaries = append(binaries, bin)
+				break
+			}
+		}
 	}
-	defer gzipReader.Close()
+	return binaries, nil
+}
+
+// isCacheTempFile returns whether the file is a leftover of an interrupted download
+func isCacheTempFile(fileName string) bool {
+	ext := filepath.Ext(fileName)
+	return ext == ".download" || ext == ".tmp" || ext == ".asc"
+}
 
-	// Create a tar reader
-	tarReader := tar.NewReader(gzipReader)
+func findRelease(name string) *release {
+	for i := range releases {
+		if releases[i].Name == name {
+			return &releases[i]
+		}
+	}
+	return nil
+}
 
-	// Extract the file
-	var found bool
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
+func downloadArtifact(url string, asset *Asset, outPath string) error {
+	// Download the archive next to the binary. If a previous download was interrupted,
+	// the partial archive is resumed instead of starting from scratch.
+	archivePath := outPath + ".download"
+	if err := fetchFile(url, archivePath); err != nil {
+		return err
+	}
+	if asset.Keyring != nil {
+		// the detached signature is published next to the archive
+		defer os.Remove(archivePath + ".asc")
+		if err := fetchFile(url+".asc", archivePath+".asc"); err != nil {
+			return fmt.Errorf("error downloading signature: %v", err)
 		}
+	}
+
+	if err := installArchive(archivePath, asset, outPath); err != nil {
+		// the archive cannot be resumed, remove it so that the next attempt starts from scratch
+		os.Remove(archivePath)
+		return err
+	}
+	return os.Remove(archivePath)
+}
+
+// installArchive verifies the archive against the checksum and the signature (if any)
+// and extracts the binary of the asset from it into outPath.
+func installArchive(archivePath string, asset *Asset, outPath string) error {
+	if asset.Checksum != "" {
+		digest, err := fileDigest(archivePath)
 		if err != nil {
-			return fmt.Errorf("error reading tar: %v", err)
+			return err
 		}
+		if !strings.EqualFold(digest, asset.Checksum) {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", archivePath, asset.Checksum, digest)
+		}
+	}
+	var signer string
+	if asset.Keyring != nil {
+		var err error
+		if signer, err = verifySignature(asset.Keyring, archivePath, archivePath+".asc"); err != nil {
+			return fmt.Errorf("signature verification failed for %s: %v", asset.Archive, err)
+		}
+	}
 
-		if header.Typeflag == tar.TypeReg {
-			if header.Name != expectedFile {
-				return fmt.Errorf("unexpected file in archive: %s", header.Name)
-			}
-			outFile, err := os.Create(outPath)
-			if err != nil {
-				return fmt.Errorf("error creating output file: %v", err)
-			}
-			defer outFile.Close()
+	// Extract the binary into a temporary file and only move it to its final
+	// path once it is complete, so that the cache never holds a partial binary.
+	tmpPath := outPath + ".tmp"
+	defer os.Remove(tmpPath)
 
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				return fmt.Errorf("error writing output file: %v", err)
-			}
+	binaryDigest, err := extractArtifact(archivePath, asset.Binary, tmpPath)
+	if err != nil {
+		return err
+	}
+	return installBinary(tmpPath, binaryDigest, signer, outPath)
+}
 
-			// change permissions
-			if err := os.Chmod(outPath, 0755); err != nil {
-				return fmt.Errorf("error changing permissions: %v", err)
-			}
-			found = true
-			break // Assuming there's only one file per repo
+// installBinary moves the binary in tmpPath to outPath and records its digest and the
+// signer of its release archive, if the signature was verified.
+func installBinary(tmpPath string, digest string, signer string, outPath string) error {
+	// do not cache binaries that cannot run on this host
+	if err := checkBinaryArch(tmpPath); err != nil {
+		return fmt.Errorf("%s cannot run on this host: %v", filepath.Base(outPath), err)
+	}
+
+	// record the digest of the binary to verify the cached copy in later runs
+	if err := os.WriteFile(outPath+".sha256", []byte(digest), 0644); err != nil {
+		return fmt.Errorf("error writing binary digest: %v", err)
+	}
+	if signer == "" {
+		if err := os.Remove(outPath + ".signed"); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing signature record: %v", err)
+		}
+	} else if err := os.WriteFile(outPath+".signed", []byte(signer), 0644); err != nil {
+		return fmt.Errorf("error writing signature record: %v", err)
+	}
+	if err := os.Rename(tmpPath, outPath); err != nil {
+		return fmt.Errorf("error moving binary into place: %v", err)
+	}
+	return nil
+}
+
+// verifyBinary checks that the cached binary matches the expected digest and returns it.
+// If no digest is expected, it uses the one recorded when the binary was downloaded.
+func verifyBinary(path string, expected string) (string, error) {
+	if expected == "" {
+		recorded, err := os.ReadFile(path + ".sha256")
+		if err != nil {
+			return "", fmt.Errorf("error reading binary digest: %v", err)
 		}
+		expected = strings.TrimSpace(string(recorded))
+	}
+	digest, err := fileDigest(path)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(digest, expected) {
+		return "", fmt.Errorf("checksum mismatch: expected %s, got %s", expected, digest)
 	}
+	return digest, nil
+}
 
-	if !found {
-		return fmt.Errorf("file not found in archive: %s", expectedFile)
+// verifySigned checks that the release archive of the cached binary was signed by one of the keys
+func verifySigned(path string, fingerprints []string) error {
+	signer, err := signedBy(path)
+	if err != nil {
+		return err
+	}
+	if signer == "" {
+		return fmt.Errorf("its release archive was not verified against a signature")
+	}
+	for _, fingerprint := range fingerprints {
+		if strings.EqualFold(signer, fingerprint) {
+			return nil
+		}
+	}
+	return fmt.Errorf("its release archive was signed by %s which is not pinned", signer)
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("error hashing %s: %v", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func removeBinary(path string) error {
+	for _, p := range []string{path, path + ".sha256", path + ".signed"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing %s: %v", p, err)
+		}
 	}
 	return nil
 }
+
+// readChecksumsManifest reads a file in the sha256sum format (<digest>  <file name>)
+// and returns the digests indexed by file name. A missing manifest is not an error.
+func readChecksumsManifest(path string) (map[string]string, error) {
+	manifest := map[string]string{}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line in %s: %q", path, line)
+		}
+		// sha256sum marks files read in binary mode with a '*' prefix
+		manifest[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
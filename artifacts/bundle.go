@@ -0,0 +1,239 @@
+package artifacts
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// bundleMetadataFile is the name of the entry in the bundle that describes the binaries.
+// It is always the first entry of the bundle.
+const bundleMetadataFile = "bundle.yaml"
+
+type bundleMetadata struct {
+	Binaries []bundleEntry `yaml:"binaries"`
+}
+
+type bundleEntry struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	File    string `yaml:"file"`
+	SHA256  string `yaml:"sha256"`
+}
+
+// Bundle packages all the binaries in the cache along with their versions and
+// digests into a gzip tarball in dst that can be imported with Import.
+func Bundle(cfg *Config, dst string) error {
+	dir, err := cacheDir(cfg)
+	if err != nil {
+		return err
+	}
+	binaries, err := listCachedBinaries(dir)
+	if err != nil {
+		return err
+	}
+	if len(binaries) == 0 {
+		return fmt.Errorf("no binaries found in %s", dir)
+	}
+
+	metadata := &bundleMetadata{}
+	for _, bin := range binaries {
+		digest, err := verifyBinary(bin.Path, "")
+		if err != nil {
+			return fmt.Errorf("%s failed verification: %v", bin.Path, err)
+		}
+		metadata.Binaries = append(metadata.Binaries, bundleEntry{
+			Name:    bin.Name,
+			Version: bin.Version,
+			File:    filepath.Base(bin.Path),
+			SHA256:  digest,
+		})
+	}
+	metadataRaw, err := yaml.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("error creating bundle: %v", err)
+	}
+	defer out.Close()
+
+	gzipWriter := gzip.NewWriter(out)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:     bundleMetadataFile,
+		Mode:     0644,
+		Size:     int64(len(metadataRaw)),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := tarWriter.Write(metadataRaw); err != nil {
+		return err
+	}
+
+	for _, bin := range binaries {
+		fmt.Printf("Adding %s\n", bin.Path)
+		if err := addFileToTar(tarWriter, bin.Path); err != nil {
+			return fmt.Errorf("error adding %s to bundle: %v", bin.Path, err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func addFileToTar(tarWriter *tar.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:     filepath.Base(path),
+		Mode:     0755,
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tarWriter, f)
+	return err
+}
+
+// Import unpacks a bundle created with Bundle into the cache. Every binary
+// is verified against the digest in the bundle metadata before it is cached.
+func Import(cfg *Config, src string) error {
+	if cfg.VerifySignatures {
+		// the digests in the bundle are not signed by the maintainers
+		return fmt.Errorf("bundles cannot be verified against the signatures of the release archives")
+	}
+
+	dir, err := cacheDir(cfg)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockCacheDir(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error opening bundle: %v", err)
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("error creating gzip reader: %v", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+
+	header, err := tarReader.Next()
+	if err != nil {
+		return fmt.Errorf("error reading bundle: %v", err)
+	}
+	if header.Name != bundleMetadataFile {
+		return fmt.Errorf("invalid bundle, expected %s as the first entry but found %s", bundleMetadataFile, header.Name)
+	}
+	metadataRaw, err := io.ReadAll(tarReader)
+	if err != nil {
+		return fmt.Errorf("error reading bundle metadata: %v", err)
+	}
+	var metadata bundleMetadata
+	if err := yaml.Unmarshal(metadataRaw, &metadata); err != nil {
+		return fmt.Errorf("error decoding bundle metadata: %v", err)
+	}
+
+	entries := map[string]bundleEntry{}
+	for _, entry := range metadata.Binaries {
+		if findRelease(entry.Name) == nil {
+			return fmt.Errorf("unknown client in bundle: %s", entry.Name)
+		}
+		if entry.File != entry.Name+"-"+entry.Version || strings.ContainsAny(entry.File, `/\`) {
+			return fmt.Errorf("invalid file name in bundle: %s", entry.File)
+		}
+		entries[entry.File] = entry
+	}
+
+	imported := 0
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading bundle: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		entry, ok := entries[header.Name]
+		if !ok {
+			return fmt.Errorf("unexpected file in bundle: %s", header.Name)
+		}
+		if err := importBinary(tarReader, entry, filepath.Join(dir, entry.File)); err != nil {
+			return fmt.Errorf("error importing %s: %v", entry.File, err)
+		}
+		fmt.Printf("Imported %s %s\n", entry.Name, entry.Version)
+		imported++
+	}
+
+	if imported != len(entries) {
+		return fmt.Errorf("bundle is incomplete, imported %d out of %d binaries", imported, len(entries))
+	}
+	return nil
+}
+
+func importBinary(r io.Reader, entry bundleEntry, outPath string) error {
+	tmpPath := outPath + ".tmp"
+	defer os.Remove(tmpPath)
+
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, hash), r); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if digest := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(digest, entry.SHA256) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", entry.SHA256, digest)
+	}
+	// the bundle has no signatures, the imported binaries are cached as unsigned
+	return installBinary(tmpPath, entry.SHA256, "", outPath)
+}
@@ -0,0 +1,142 @@
+package artifacts
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cacheTestBinary installs the test binary in the cache as <name>-<version>
+func cacheTestBinary(t *testing.T, dir string, name, version string) {
+	t.Helper()
+
+	tmpPath := filepath.Join(dir, "bin.tmp")
+	if err := os.WriteFile(tmpPath, testBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := installBinary(tmpPath, sha256Hex(testBinary), "", filepath.Join(dir, name+"-"+version)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBundleImport(t *testing.T) {
+	src := &Config{CacheDir: t.TempDir()}
+	cacheTestBinary(t, src.CacheDir, "reth", "v1.0.0")
+	cacheTestBinary(t, src.CacheDir, "lighthouse", "v5.0.0")
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := Bundle(src, bundlePath); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &Config{CacheDir: t.TempDir()}
+	if err := Import(dst, bundlePath); err != nil {
+		t.Fatal(err)
+	}
+	binaries, err := listCachedBinaries(dst.CacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(binaries) != 2 {
+		t.Fatalf("expected 2 binaries but got %d", len(binaries))
+	}
+	for _, bin := range binaries {
+		checkInstalled(t, bin.Path)
+	}
+}
+
+func TestImportTampered(t *testing.T) {
+	src := &Config{CacheDir: t.TempDir()}
+	cacheTestBinary(t, src.CacheDir, "reth", "v1.0.0")
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := Bundle(src, bundlePath); err != nil {
+		t.Fatal(err)
+	}
+	// replace the binary in the bundle but keep the digest of the metadata
+	tamperBundle(t, bundlePath, func(name string, data []byte) []byte {
+		if name == bundleMetadataFile {
+			return data
+		}
+		return []byte("#!/bin/sh\necho tampered\n")
+	})
+
+	dst := &Config{CacheDir: t.TempDir()}
+	err := Import(dst, bundlePath)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch but got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst.CacheDir, "reth-v1.0.0")); !os.IsNotExist(err) {
+		t.Fatal("the tampered binary was imported")
+	}
+}
+
+func TestImportVerifySignatures(t *testing.T) {
+	src := &Config{CacheDir: t.TempDir()}
+	cacheTestBinary(t, src.CacheDir, "reth", "v1.0.0")
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := Bundle(src, bundlePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := Import(&Config{CacheDir: t.TempDir(), VerifySignatures: true}, bundlePath); err == nil {
+		t.Fatal("expected an error importing a bundle with signature verification")
+	}
+}
+
+// tamperBundle rewrites every entry of the bundle with the output of fn
+func tamperBundle(t *testing.T, path string, fn func(name string, data []byte) []byte) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = fn(header.Name, data)
+		header.Size = int64(len(data))
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,126 @@
+package artifacts
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ListCache returns the binaries in the cache and flags the ones used by the configuration
+func ListCache(cfg *Config) ([]*CachedBinary, error) {
+	versions, _, err := resolveVersions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := cacheDir(cfg)
+	if err != nil {
+		return nil, err
+	}
+	binaries, err := listCachedBinaries(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, bin := range binaries {
+		bin.InUse = versions[bin.Name] == bin.Version
+	}
+	return binaries, nil
+}
+
+// VerifyCache hashes every binary in the cache again and checks that it can be executed.
+// It returns an error if any of the binaries fails the verification.
+func VerifyCache(cfg *Config) error {
+	dir, err := cacheDir(cfg)
+	if err != nil {
+		return err
+	}
+	binaries, err := listCachedBinaries(dir)
+	if err != nil {
+		return err
+	}
+
+	failed := []string{}
+	for _, bin := range binaries {
+		if err := verifyCachedBinary(bin); err != nil {
+			fmt.Printf("FAIL %s: %v\n", bin.Path, err)
+			failed = append(failed, filepath.Base(bin.Path))
+		} else {
+			fmt.Printf("OK   %s\n", bin.Path)
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("%d binaries failed verification: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func verifyCachedBinary(bin *CachedBinary) error {
+	if _, err := verifyBinary(bin.Path, ""); err != nil {
+		return err
+	}
+	// make sure you can run the binary, both reth and lighthouse have the --version flag
+	if out, err := exec.Command(bin.Path, "--version").CombinedOutput(); err != nil {
+		return fmt.Errorf("error running %s --version: %v: %s", bin.Path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// PruneCache removes the binaries that are not referenced by the lockfile along with the
+// leftovers of interrupted downloads. It returns the paths of the removed files.
+func PruneCache(cfg *Config) ([]string, error) {
+	if cfg.LockFile == "" {
+		return nil, fmt.Errorf("a lockfile is required to prune the cache")
+	}
+	if _, err := os.Stat(cfg.LockFile); err != nil {
+		return nil, fmt.Errorf("error reading lockfile: %v", err)
+	}
+	lock, err := readLockFile(cfg.LockFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading lockfile: %v", err)
+	}
+
+	dir, err := cacheDir(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockCacheDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	binaries, err := listCachedBinaries(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+	for _, bin := range binaries {
+		if locked, ok := lock[bin.Name]; ok && locked.Version == bin.Version {
+			continue
+		}
+		if err := removeBinary(bin.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, bin.Path)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return removed, err
+	}
+	for _, entry := range entries {
+		if !isCacheTempFile(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,227 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// downloadAttempts is the number of times a download is tried before giving up
+	downloadAttempts = 5
+
+	// downloadBackoff is the wait time before the first retry, it doubles on every retry
+	downloadBackoff = 2 * time.Second
+
+	// downloadIdleTimeout aborts a download attempt if no data is received for this long
+	downloadIdleTimeout = 30 * time.Second
+)
+
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+}
+
+// errPermanent wraps the download errors that should not be retried
+type errPermanent struct {
+	err error
+}
+
+func (e *errPermanent) Error() string {
+	return e.err.Error()
+}
+
+// fetchFile downloads url into dst. If dst already holds part of the file from
+// an interrupted download, the transfer is resumed with an HTTP range request.
+// Failed attempts are retried with exponential backoff.
+func fetchFile(url string, dst string) error {
+	backoff := downloadBackoff
+
+	var err error
+	for attempt := 1; attempt <= downloadAttempts; attempt++ {
+		if err = fetchFileAttempt(url, dst); err == nil {
+			return nil
+		}
+
+		var permanentErr *errPermanent
+		if errors.As(err, &permanentErr) || attempt == downloadAttempts {
+			break
+		}
+
+		fmt.Printf("Download attempt %d/%d failed: %v. Retrying in %s\n", attempt, downloadAttempts, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return fmt.Errorf("error downloading file: %v", err)
+}
+
+func fetchFileAttempt(url string, dst string) error {
+	var offset int64
+	if info, err := os.Stat(dst); err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
+		return &errPermanent{err}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// cancel the request if the transfer stalls
+	idleTimer := time.AfterFunc(downloadIdleTimeout, cancel)
+	defer idleTimer.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return &errPermanent{err}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// the server does not support ranges (or there was nothing to resume), start from scratch
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusPartialContent:
+		if start := contentRangeStart(resp.Header.Get("Content-Range")); start != offset {
+			return &errPermanent{fmt.Errorf("unexpected content range %q for offset %d", resp.Header.Get("Content-Range"), offset)}
+		}
+		fmt.Printf("Resuming download at %d bytes\n", offset)
+		flags |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is already complete
+		return nil
+	default:
+		err := fmt.Errorf("unexpected status %s", resp.Status)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return &errPermanent{err}
+		}
+		return err
+	}
+
+	out, err := os.OpenFile(dst, flags, 0644)
+	if err != nil {
+		return &errPermanent{err}
+	}
+	defer out.Close()
+
+	var total int64 = -1
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	progress := newProgressWriter(offset, total, func() {
+		idleTimer.Reset(downloadIdleTimeout)
+	})
+	defer progress.Finish()
+
+	if _, err := io.Copy(io.MultiWriter(out, progress), resp.Body); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("no data received in %s", downloadIdleTimeout)
+		}
+		return err
+	}
+	return out.Close()
+}
+
+// contentRangeStart returns the first byte of a 'bytes <start>-<end>/<size>' header
+func contentRangeStart(header string) int64 {
+	rng, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return -1
+	}
+	start, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return -1
+	}
+	num, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return num
+}
+
+// progressWriter prints the progress of a download: bytes, percentage and transfer rate
+type progressWriter struct {
+	written   int64
+	total     int64
+	offset    int64
+	start     time.Time
+	lastPrint time.Time
+	onWrite   func()
+}
+
+func newProgressWriter(offset, total int64, onWrite func()) *progressWriter {
+	return &progressWriter{
+		written: offset,
+		offset:  offset,
+		total:   total,
+		start:   time.Now(),
+		onWrite: onWrite,
+	}
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if p.onWrite != nil {
+		p.onWrite()
+	}
+	if time.Since(p.lastPrint) > 500*time.Millisecond {
+		p.print()
+	}
+	return len(b), nil
+}
+
+func (p *progressWriter) print() {
+	p.lastPrint = time.Now()
+
+	var rate float64
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		rate = float64(p.written-p.offset) / elapsed
+	}
+
+	if p.total > 0 {
+		fmt.Printf("\r  %s / %s (%d%%) %s/s   ", formatBytes(p.written), formatBytes(p.total), p.written*100/p.total, formatBytes(int64(rate)))
+	} else {
+		fmt.Printf("\r  %s %s/s   ", formatBytes(p.written), formatBytes(int64(rate)))
+	}
+}
+
+func (p *progressWriter) Finish() {
+	p.print()
+	fmt.Println()
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,190 @@
+package artifacts
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+type archiveFormat string
+
+const (
+	formatTarGz archiveFormat = "tar.gz"
+	formatTarXz archiveFormat = "tar.xz"
+	formatZip   archiveFormat = "zip"
+	formatRaw   archiveFormat = "raw"
+)
+
+// magic numbers of the supported formats
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zipMagic  = []byte{'P', 'K', 0x03, 0x04}
+
+	// executable formats that are cached as they are
+	elfMagic    = []byte{0x7f, 'E', 'L', 'F'}
+	machoMagics = [][]byte{
+		{0xfe, 0xed, 0xfa, 0xce}, {0xce, 0xfa, 0xed, 0xfe}, // 32 bits
+		{0xfe, 0xed, 0xfa, 0xcf}, {0xcf, 0xfa, 0xed, 0xfe}, // 64 bits
+		{0xca, 0xfe, 0xba, 0xbe}, // universal binary
+	}
+)
+
+// detectFormat figures out the format of the archive from its first bytes
+func detectFormat(r io.ReaderAt) (archiveFormat, error) {
+	header := make([]byte, 8)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return formatTarGz, nil
+	case bytes.HasPrefix(header, xzMagic):
+		return formatTarXz, nil
+	case bytes.HasPrefix(header, zipMagic):
+		return formatZip, nil
+	case bytes.HasPrefix(header, elfMagic):
+		return formatRaw, nil
+	}
+	for _, magic := range machoMagics {
+		if bytes.HasPrefix(header, magic) {
+			return formatRaw, nil
+		}
+	}
+	return "", fmt.Errorf("unknown archive format")
+}
+
+// matchesBinary returns whether the entry of an archive is the expected binary.
+// The binary can be nested in any directory of the archive unless the expected
+// name includes the directory.
+func matchesBinary(name string, expectedFile string) bool {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if strings.Contains(expectedFile, "/") {
+		return name == expectedFile
+	}
+	return path.Base(name) == expectedFile
+}
+
+// extractArtifact extracts the expected file from the archive into outPath
+// and returns the sha256 digest of the extracted file.
+func extractArtifact(archivePath string, expectedFile string, outPath string) (string, error) {
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("error opening archive: %v", err)
+	}
+	defer archive.Close()
+
+	format, err := detectFormat(archive)
+	if err != nil {
+		return "", fmt.Errorf("error detecting format of %s: %v", archivePath, err)
+	}
+
+	var binary io.Reader
+	switch format {
+	case formatTarGz:
+		// Create a gzip reader
+		gzipReader, err := gzip.NewReader(archive)
+		if err != nil {
+			return "", fmt.Errorf("error creating gzip reader: %v", err)
+		}
+		defer gzipReader.Close()
+
+		if binary, err = findInTar(gzipReader, expectedFile); err != nil {
+			return "", err
+		}
+
+	case formatTarXz:
+		xzReader, err := xz.NewReader(archive)
+		if err != nil {
+			return "", fmt.Errorf("error creating xz reader: %v", err)
+		}
+		if binary, err = findInTar(xzReader, expectedFile); err != nil {
+			return "", err
+		}
+
+	case formatZip:
+		info, err := archive.Stat()
+		if err != nil {
+			return "", err
+		}
+		zipReader, err := zip.NewReader(archive, info.Size())
+		if err != nil {
+			return "", fmt.Errorf("error reading zip: %v", err)
+		}
+
+		var entry *zip.File
+		for _, f := range zipReader.File {
+			if f.Mode().IsRegular() && matchesBinary(f.Name, expectedFile) {
+				entry = f
+				break
+			}
+		}
+		if entry == nil {
+			return "", fmt.Errorf("file not found in archive: %s", expectedFile)
+		}
+		entryReader, err := entry.Open()
+		if err != nil {
+			return "", fmt.Errorf("error reading zip: %v", err)
+		}
+		defer entryReader.Close()
+		binary = entryReader
+
+	case formatRaw:
+		// the release asset is the binary itself
+		binary = archive
+	}
+
+	outFile, err := os.OpenFile(outPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return "", fmt.Errorf("error creating output file: %v", err)
+	}
+	defer outFile.Close()
+
+	binaryHash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(outFile, binaryHash), binary); err != nil {
+		return "", fmt.Errorf("error writing output file: %v", err)
+	}
+	if err := outFile.Close(); err != nil {
+		return "", fmt.Errorf("error writing output file: %v", err)
+	}
+
+	// change permissions
+	if err := os.Chmod(outPath, 0755); err != nil {
+		return "", fmt.Errorf("error changing permissions: %v", err)
+	}
+	return hex.EncodeToString(binaryHash.Sum(nil)), nil
+}
+
+// findInTar advances the tar stream up to the expected file and returns a reader for it
+func findInTar(r io.Reader, expectedFile string) (io.Reader, error) {
+	// Create a tar reader
+	tarReader := tar.NewReader(r)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading tar: %v", err)
+		}
+
+		if header.Typeflag == tar.TypeReg && matchesBinary(header.Name, expectedFile) {
+			return tarReader, nil
+		}
+	}
+	return nil, fmt.Errorf("file not found in archive: %s", expectedFile)
+}
@@ -0,0 +1,144 @@
+package artifacts
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+// testBinary is a script that passes the arch check on any host
+var testBinary = []byte("#!/bin/sh\necho test\n")
+
+// testArchive returns an archive of the given format with the files indexed by name
+func testArchive(t *testing.T, format archiveFormat, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	switch format {
+	case formatTarGz, formatTarXz:
+		var w interface {
+			Write([]byte) (int, error)
+			Close() error
+		}
+		if format == formatTarGz {
+			w = gzip.NewWriter(&buf)
+		} else {
+			xzWriter, err := xz.NewWriter(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w = xzWriter
+		}
+		tarWriter := tar.NewWriter(w)
+		for name, data := range files {
+			if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tarWriter.Write(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tarWriter.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+	case formatZip:
+		zipWriter := zip.NewWriter(&buf)
+		for name, data := range files {
+			f, err := zipWriter.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Write(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := zipWriter.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+	default:
+		t.Fatalf("unsupported format %s", format)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractArtifact(t *testing.T) {
+	// raw assets must be executables, use the test binary itself
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawBinary, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nested := map[string][]byte{
+		"reth-v1.0.0/README.md":  []byte("readme"),
+		"reth-v1.0.0/bin/reth":   testBinary,
+		"reth-v1.0.0/bin/reth.d": []byte("not the binary"),
+	}
+
+	cases := []struct {
+		name     string
+		archive  []byte
+		expected []byte
+	}{
+		{"tar.gz", testArchive(t, formatTarGz, nested), testBinary},
+		{"tar.xz", testArchive(t, formatTarXz, nested), testBinary},
+		{"zip", testArchive(t, formatZip, nested), testBinary},
+		{"raw", rawBinary, rawBinary},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, "archive")
+			if err := os.WriteFile(archivePath, c.archive, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			outPath := filepath.Join(dir, "reth")
+			digest, err := extractArtifact(archivePath, "reth", outPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(outPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, c.expected) {
+				t.Fatal("extracted the wrong file")
+			}
+			if expected, _ := fileDigest(outPath); digest != expected {
+				t.Fatalf("expected digest %s but got %s", expected, digest)
+			}
+			if info, _ := os.Stat(outPath); info.Mode().Perm()&0100 == 0 {
+				t.Fatal("the binary is not executable")
+			}
+		})
+	}
+}
+
+func TestExtractArtifactNotFound(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "archive")
+	archive := testArchive(t, formatTarGz, map[string][]byte{"lighthouse": testBinary})
+	if err := os.WriteFile(archivePath, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := extractArtifact(archivePath, "reth", filepath.Join(dir, "reth")); err == nil {
+		t.Fatal("expected an error for a missing binary")
+	}
+}
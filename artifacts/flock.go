@@ -0,0 +1,39 @@
+package artifacts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// cacheLockFile is the file under the cache directory used to serialize
+// the processes that write into the cache
+const cacheLockFile = ".cache.lock"
+
+// lockCacheDir takes an exclusive cross-process lock on the cache directory and
+// blocks until it is available. The returned function releases the lock.
+func lockCacheDir(dir string) (func(), error) {
+	path := filepath.Join(dir, cacheLockFile)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening cache lock: %v", err)
+	}
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		fmt.Printf("Waiting for another process to release the cache lock %s\n", path)
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error locking cache: %v", err)
+	}
+
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
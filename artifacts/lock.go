@@ -0,0 +1,85 @@
+package artifacts
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// lockFile pins the version and the digest of the binary of each client
+type lockFile map[string]lockEntry
+
+type lockEntry struct {
+	Version string `yaml:"version"`
+
+	// SHA256 are the digests of the binary indexed by platform (<goos>/<goarch>),
+	// the binaries of the same version are different for every platform
+	SHA256 map[string]string `yaml:"sha256"`
+}
+
+func (l *lockEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var entry struct {
+		Version string      `yaml:"version"`
+		SHA256  interface{} `yaml:"sha256"`
+	}
+	if err := unmarshal(&entry); err != nil {
+		return err
+	}
+	l.Version = entry.Version
+	l.SHA256 = map[string]string{}
+
+	switch digests := entry.SHA256.(type) {
+	case nil, string:
+		// older lockfiles pinned a single digest without its platform, it is
+		// recorded again for the platform of the next run
+	case map[interface{}]interface{}:
+		for platform, digest := range digests {
+			platformStr, ok1 := platform.(string)
+			digestStr, ok2 := digest.(string)
+			if !ok1 || !ok2 {
+				return fmt.Errorf("invalid sha256 entry for %v", platform)
+			}
+			l.SHA256[platformStr] = digestStr
+		}
+	default:
+		return fmt.Errorf("invalid sha256 field")
+	}
+	return nil
+}
+
+// platform is the key of the digests of the host in the lockfile
+func platform(goos, goarch string) string {
+	return goos + "/" + goarch
+}
+
+// readLockFile reads the lockfile in path. A missing lockfile is not an error.
+func readLockFile(path string) (lockFile, error) {
+	lock := lockFile{}
+	if path == "" {
+		return lock, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+func writeLockFile(path string, lock lockFile) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
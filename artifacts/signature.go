@@ -0,0 +1,106 @@
+package artifacts
+
+import (
+	"bytes"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// signingKeysFS holds the armored keys of the maintainers as keys/<FINGERPRINT>.asc
+//
+//go:embed keys
+var signingKeysFS embed.FS
+
+// loadSigningKeys returns the keys with the given fingerprints. Every key is looked up
+// in the keyring file (if any) and then in the keys embedded in the playground.
+func loadSigningKeys(fingerprints []string, keyringPath string) (openpgp.EntityList, error) {
+	var keyring openpgp.EntityList
+	if keyringPath != "" {
+		data, err := os.ReadFile(keyringPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading keyring: %v", err)
+		}
+		if keyring, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("error decoding keyring %s: %v", keyringPath, err)
+		}
+	}
+
+	keys := openpgp.EntityList{}
+	for _, fingerprint := range fingerprints {
+		if key := findKey(keyring, fingerprint); key != nil {
+			keys = append(keys, key)
+			continue
+		}
+
+		data, err := signingKeysFS.ReadFile("keys/" + strings.ToUpper(fingerprint) + ".asc")
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("key %s is not embedded, pass it with --signing-keyring", fingerprint)
+		} else if err != nil {
+			return nil, err
+		}
+
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding key %s: %v", fingerprint, err)
+		}
+		key := findKey(entities, fingerprint)
+		if key == nil {
+			return nil, fmt.Errorf("key %s not found", fingerprint)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// findKey returns the key in the keyring with the given fingerprint
+func findKey(keyring openpgp.EntityList, fingerprint string) *openpgp.Entity {
+	for _, entity := range keyring {
+		if strings.EqualFold(hex.EncodeToString(entity.PrimaryKey.Fingerprint), fingerprint) {
+			return entity
+		}
+	}
+	return nil
+}
+
+// verifySignature checks the armored detached signature in sigPath of the file in path
+// and returns the fingerprint of the signer
+func verifySignature(keyring openpgp.EntityList, path string, sigPath string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sig, err := os.Open(sigPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading signature: %v", err)
+	}
+	defer sig.Close()
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, f, sig, nil)
+	if err != nil {
+		return "", err
+	}
+	fingerprint := strings.ToUpper(hex.EncodeToString(signer.PrimaryKey.Fingerprint))
+	fmt.Printf("Good signature from %s\n", fingerprint)
+	return fingerprint, nil
+}
+
+// signedBy returns the fingerprint of the key that signed the release archive of the
+// cached binary, or an empty string if the archive was not verified against a signature
+func signedBy(path string) (string, error) {
+	data, err := os.ReadFile(path + ".signed")
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("error reading signature record: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
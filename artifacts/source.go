@@ -0,0 +1,155 @@
+package artifacts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// defaultSourceURL is the base URL of the GitHub releases of the clients
+const defaultSourceURL = "https://github.com"
+
+// Asset identifies the release of a client for a given architecture
+type Asset struct {
+	Name    string
+	Org     string
+	Version string
+	Arch    string
+
+	// Archive is the file name of the release archive
+	Archive string
+
+	// Binary is the name of the binary inside the release archive
+	Binary string
+
+	// Checksum is the expected sha256 digest of the release archive, if known
+	Checksum string
+
+	// Keyring holds the keys that sign the release archive. If set, the
+	// archive is refused unless it has a valid signature from one of them.
+	Keyring openpgp.EntityList
+
+	// AllowUnverified accepts the archive even if there is neither a checksum nor a keyring
+	AllowUnverified bool
+}
+
+// checkVerifiable refuses the archives that cannot be verified unless it is explicitly allowed
+func checkVerifiable(asset *Asset) error {
+	if asset.Checksum != "" || asset.Keyring != nil {
+		return nil
+	}
+	if !asset.AllowUnverified {
+		return fmt.Errorf("no checksum found for %s, add it to the checksums manifest, use --verify-signatures or --allow-unverified to skip the verification", asset.Archive)
+	}
+	fmt.Printf("No checksum found for %s, skipping archive verification\n", asset.Archive)
+	return nil
+}
+
+// Source fetches the binaries of the clients
+type Source interface {
+	// Fetch stores the binary of the asset in dst along with its digest
+	Fetch(asset *Asset, dst string) error
+}
+
+// NewSource creates a source from its description:
+// - empty: the GitHub releases page of each client.
+// - http(s)://<base>: a mirror of the GitHub releases with the same path layout (<base>/<org>/<name>/releases/download/<version>/<archive>).
+// - file://<dir>: a directory with the release archives (<dir>/<archive>).
+// - <dir>: a directory with prebuilt binaries (<dir>/<name>-<version> or <dir>/<name>).
+func NewSource(source string) (Source, error) {
+	if source == "" {
+		return &httpSource{baseURL: defaultSourceURL}, nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifacts source %s: %v", source, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return &httpSource{baseURL: strings.TrimSuffix(source, "/")}, nil
+	case "file":
+		return &fileSource{dir: u.Path}, nil
+	case "":
+		return &dirSource{dir: source}, nil
+	default:
+		return nil, fmt.Errorf("unsupported artifacts source scheme: %s", u.Scheme)
+	}
+}
+
+// httpSource downloads the release archives from GitHub or a mirror with the same layout
+type httpSource struct {
+	baseURL string
+}
+
+func (h *httpSource) Fetch(asset *Asset, dst string) error {
+	releasesURL := fmt.Sprintf("%s/%s/%s/releases/download/%s/%s", h.baseURL, asset.Org, asset.Name, asset.Version, asset.Archive)
+	if err := checkVerifiable(asset); err != nil {
+		return err
+	}
+	fmt.Printf("Downloading %s: %s\n", dst, releasesURL)
+	return downloadArtifact(releasesURL, asset, dst)
+}
+
+// fileSource extracts the binaries from release archives in a local directory
+type fileSource struct {
+	dir string
+}
+
+func (f *fileSource) Fetch(asset *Asset, dst string) error {
+	archivePath := filepath.Join(f.dir, asset.Archive)
+	if err := checkVerifiable(asset); err != nil {
+		return err
+	}
+	fmt.Printf("Extracting %s: %s\n", dst, archivePath)
+	return installArchive(archivePath, asset, dst)
+}
+
+// dirSource copies prebuilt binaries from a local directory
+type dirSource struct {
+	dir string
+}
+
+func (d *dirSource) Fetch(asset *Asset, dst string) error {
+	if asset.Keyring != nil {
+		return fmt.Errorf("prebuilt binaries in %s cannot be verified against a signature", d.dir)
+	}
+
+	// prefer the binary for the specific version if there is one
+	srcPath := filepath.Join(d.dir, asset.Name+"-"+asset.Version)
+	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+		srcPath = filepath.Join(d.dir, asset.Name)
+	}
+	fmt.Printf("Copying %s: %s\n", dst, srcPath)
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("error opening binary: %v", err)
+	}
+	defer src.Close()
+
+	tmpPath := dst + ".tmp"
+	defer os.Remove(tmpPath)
+
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %v", err)
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, hash), src); err != nil {
+		return fmt.Errorf("error copying binary: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("error copying binary: %v", err)
+	}
+	return installBinary(tmpPath, hex.EncodeToString(hash.Sum(nil)), "", dst)
+}
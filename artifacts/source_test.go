@@ -0,0 +1,293 @@
+package artifacts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func testAsset(archive []byte) *Asset {
+	return &Asset{
+		Name:     "reth",
+		Org:      "paradigmxyz",
+		Version:  "v1.0.0",
+		Arch:     "x86_64-unknown-linux-gnu",
+		Archive:  "reth-v1.0.0-x86_64-unknown-linux-gnu.tar.gz",
+		Binary:   "reth",
+		Checksum: sha256Hex(archive),
+	}
+}
+
+// checkInstalled checks that the binary in path is the test binary with its digest recorded
+func checkInstalled(t *testing.T, path string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, testBinary) {
+		t.Fatal("unexpected binary")
+	}
+	if _, err := verifyBinary(path, sha256Hex(testBinary)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyBinary(path, ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func withFastRetries(t *testing.T) {
+	attempts, backoff := downloadAttempts, downloadBackoff
+	downloadAttempts, downloadBackoff = 3, time.Millisecond
+	t.Cleanup(func() {
+		downloadAttempts, downloadBackoff = attempts, backoff
+	})
+}
+
+func TestHTTPSourceResume(t *testing.T) {
+	archive := testArchive(t, formatTarGz, map[string][]byte{"reth-v1.0.0/reth": testBinary})
+	asset := testAsset(archive)
+
+	var rangeHeader atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/paradigmxyz/reth/releases/download/v1.0.0/"+asset.Archive {
+			http.NotFound(w, r)
+			return
+		}
+		rangeHeader.Store(r.Header.Get("Range"))
+		http.ServeContent(w, r, asset.Archive, time.Time{}, bytes.NewReader(archive))
+	}))
+	defer srv.Close()
+
+	// leave half of the archive from an interrupted download
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	half := len(archive) / 2
+	if err := os.WriteFile(dst+".download", archive[:half], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&httpSource{baseURL: srv.URL}).Fetch(asset, dst); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "bytes=" + strconv.Itoa(half) + "-"; rangeHeader.Load() != expected {
+		t.Fatalf("expected range %s but got %v", expected, rangeHeader.Load())
+	}
+	checkInstalled(t, dst)
+	if _, err := os.Stat(dst + ".download"); !os.IsNotExist(err) {
+		t.Fatal("the archive was not removed")
+	}
+}
+
+func TestHTTPSourceNoRangeSupport(t *testing.T) {
+	archive := testArchive(t, formatTarGz, map[string][]byte{"reth": testBinary})
+	asset := testAsset(archive)
+
+	// the server ignores the range and always returns the full archive
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	if err := os.WriteFile(dst+".download", []byte("partial content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&httpSource{baseURL: srv.URL}).Fetch(asset, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkInstalled(t, dst)
+}
+
+func TestHTTPSourceNotFound(t *testing.T) {
+	withFastRetries(t)
+
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	if err := (&httpSource{baseURL: srv.URL}).Fetch(testAsset(nil), dst); err == nil {
+		t.Fatal("expected an error")
+	}
+	// a 404 is permanent and it is not retried
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("expected 1 request but got %d", n)
+	}
+}
+
+func TestHTTPSourceRetry(t *testing.T) {
+	withFastRetries(t)
+
+	archive := testArchive(t, formatTarGz, map[string][]byte{"reth": testBinary})
+
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	if err := (&httpSource{baseURL: srv.URL}).Fetch(testAsset(archive), dst); err != nil {
+		t.Fatal(err)
+	}
+	checkInstalled(t, dst)
+}
+
+func TestHTTPSourceChecksumMismatch(t *testing.T) {
+	archive := testArchive(t, formatTarGz, map[string][]byte{"reth": testBinary})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	asset := testAsset(archive)
+	asset.Checksum = sha256Hex([]byte("another archive"))
+
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	err := (&httpSource{baseURL: srv.URL}).Fetch(asset, dst)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch but got %v", err)
+	}
+	// neither the binary nor the archive are kept
+	for _, path := range []string{dst, dst + ".sha256", dst + ".download"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s was not removed", path)
+		}
+	}
+}
+
+func TestHTTPSourceUnverified(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	}))
+	defer srv.Close()
+
+	asset := testAsset(nil)
+	asset.Checksum = ""
+
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	if err := (&httpSource{baseURL: srv.URL}).Fetch(asset, dst); err == nil {
+		t.Fatal("expected an error for an archive that cannot be verified")
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	archive := testArchive(t, formatZip, map[string][]byte{"bin/reth": testBinary})
+	asset := testAsset(archive)
+	asset.Archive = "reth-v1.0.0-x86_64-unknown-linux-gnu.zip"
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, asset.Archive), archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := NewSource("file://" + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	if err := source.Fetch(asset, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkInstalled(t, dst)
+
+	// the archive in the directory is not removed
+	if _, err := os.Stat(filepath.Join(dir, asset.Archive)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "reth"), []byte("#!/bin/sh\necho other\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the binary of the specific version takes precedence
+	if err := os.WriteFile(filepath.Join(dir, "reth-v1.0.0"), testBinary, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := NewSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	if err := source.Fetch(testAsset(nil), dst); err != nil {
+		t.Fatal(err)
+	}
+	checkInstalled(t, dst)
+}
+
+func TestFileSourceSignature(t *testing.T) {
+	archive := testArchive(t, formatTarGz, map[string][]byte{"reth": testBinary})
+	asset := testAsset(archive)
+	asset.Checksum = ""
+
+	key, err := openpgp.NewEntity("maintainer", "", "maintainer@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, key, bytes.NewReader(archive), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, asset.Archive), archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, asset.Archive+".asc"), sig.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := openpgp.NewEntity("other", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "reth-v1.0.0")
+	asset.Keyring = openpgp.EntityList{other}
+	if err := (&fileSource{dir: dir}).Fetch(asset, dst); err == nil {
+		t.Fatal("expected an error for a signature from an unknown key")
+	}
+
+	asset.Keyring = openpgp.EntityList{key}
+	if err := (&fileSource{dir: dir}).Fetch(asset, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkInstalled(t, dst)
+
+	// the signer is recorded along with the binary
+	fingerprint := hex.EncodeToString(key.PrimaryKey.Fingerprint)
+	if err := verifySigned(dst, []string{fingerprint}); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySigned(dst, []string{hex.EncodeToString(other.PrimaryKey.Fingerprint)}); err == nil {
+		t.Fatal("expected an error for a signer that is not pinned")
+	}
+}
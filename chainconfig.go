@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"gopkg.in/yaml.v2"
+)
+
+// chainConfigOverlay are the overrides of the consensus and execution chain configs.
+// The consensus values use the keys of config.yaml (e.g. SECONDS_PER_SLOT) and the
+// execution values use the path of the field in genesis.json (e.g. config.chainId or gasLimit).
+type chainConfigOverlay struct {
+	CL map[string]string `yaml:"cl"`
+	EL map[string]string `yaml:"el"`
+}
+
+// readChainConfigOverlay reads the overlay file (if any) and applies the KEY=VALUE flags on top of it
+func readChainConfigOverlay(path string, clFlags, elFlags []string) (*chainConfigOverlay, error) {
+	overlay := &chainConfigOverlay{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading chain config: %v", err)
+		}
+		if err := yaml.UnmarshalStrict(data, overlay); err != nil {
+			return nil, fmt.Errorf("error decoding chain config %s: %v", path, err)
+		}
+	}
+	if overlay.CL == nil {
+		overlay.CL = map[string]string{}
+	}
+	if overlay.EL == nil {
+		overlay.EL = map[string]string{}
+	}
+
+	for _, flags := range []struct {
+		values []string
+		dst    map[string]string
+	}{{clFlags, overlay.CL}, {elFlags, overlay.EL}} {
+		for _, flag := range flags.values {
+			key, value, ok := strings.Cut(flag, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("invalid chain config '%s', expected KEY=VALUE", flag)
+			}
+			flags.dst[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return overlay, nil
+}
+
+// ApplyCL sets the consensus values on the beacon chain config. It must be
+// called before params.SetActive.
+func (c *chainConfigOverlay) ApplyCL(config *params.BeaconChainConfig) error {
+	if len(c.CL) == 0 {
+		return nil
+	}
+
+	lines := []string{}
+	for _, key := range sortedKeys(c.CL) {
+		if strings.HasSuffix(key, "_FORK_EPOCH") {
+			return fmt.Errorf("%s cannot be set in the chain config, use --fork-epoch instead", key)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", key, c.CL[key]))
+	}
+
+	// UnmarshalConfig only logs the type errors, decode the values first to
+	// report unknown keys or invalid values
+	converted := make([]string, len(lines))
+	for i, line := range lines {
+		converted[i] = line
+		if !strings.HasPrefix(line, "DEPOSIT_CONTRACT_ADDRESS") && strings.Contains(line, "0x") {
+			converted[i] = strings.Join(params.ReplaceHexStringWithYAMLFormat(line), "\n")
+		}
+	}
+	if err := yaml.UnmarshalStrict([]byte(strings.Join(converted, "\n")), &params.BeaconChainConfig{}); err != nil {
+		return fmt.Errorf("invalid consensus chain config: %v", err)
+	}
+
+	// CONFIG_NAME is reset if it is not in the overlay
+	if _, ok := c.CL["CONFIG_NAME"]; !ok {
+		lines = append(lines, "CONFIG_NAME: "+config.ConfigName)
+	}
+	if _, err := params.UnmarshalConfig([]byte(strings.Join(lines, "\n")), config); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ApplyEL sets the execution values on the genesis
+func (c *chainConfigOverlay) ApplyEL(gen *core.Genesis) error {
+	if len(c.EL) == 0 {
+		return nil
+	}
+
+	obj, err := genesisToJSONObject(gen)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(c.EL) {
+		switch key {
+		case "config.shanghaiTime", "config.cancunTime", "config.pragueTime":
+			return fmt.Errorf("%s cannot be set in the chain config, use --fork-epoch instead", key)
+		case "timestamp":
+			return fmt.Errorf("timestamp cannot be set in the chain config, use GENESIS_DELAY in the consensus config instead")
+		case "alloc":
+			return fmt.Errorf("alloc cannot be set in the chain config, use --genesis-alloc instead")
+		}
+		if err := setJSONPath(obj, strings.Split(key, "."), jsonValue(c.EL[key])); err != nil {
+			return fmt.Errorf("invalid execution chain config '%s': %v", key, err)
+		}
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	newGen := &core.Genesis{}
+	if err := json.Unmarshal(data, newGen); err != nil {
+		return fmt.Errorf("invalid execution chain config: %v", err)
+	}
+
+	// the genesis ignores the unknown fields, make sure that all the values are used
+	newObj, err := genesisToJSONObject(newGen)
+	if err != nil {
+		return err
+	}
+	for key := range c.EL {
+		if !hasJSONPath(newObj, strings.Split(key, ".")) {
+			return fmt.Errorf("unknown execution chain config '%s'", key)
+		}
+	}
+
+	*gen = *newGen
+	return nil
+}
+
+// genesisToJSONObject encodes the genesis as a generic json object, the numbers
+// are kept as json.Number to avoid losing precision
+func genesisToJSONObject(gen *core.Genesis) (map[string]interface{}, error) {
+	data, err := json.Marshal(gen)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// jsonValue returns the raw value as a json number or boolean if possible, or as a string otherwise
+func jsonValue(raw string) interface{} {
+	if _, err := strconv.ParseUint(raw, 10, 64); err == nil {
+		return json.Number(raw)
+	}
+	if b, err := strconv.ParseBool(raw); err == nil {
+		return b
+	}
+	return raw
+}
+
+func setJSONPath(obj map[string]interface{}, path []string, value interface{}) error {
+	if len(path) == 1 {
+		obj[path[0]] = value
+		return nil
+	}
+	child, ok := obj[path[0]]
+	if !ok {
+		child = map[string]interface{}{}
+		obj[path[0]] = child
+	}
+	childObj, ok := child.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("'%s' is not an object", path[0])
+	}
+	return setJSONPath(childObj, path[1:], value)
+}
+
+func hasJSONPath(obj map[string]interface{}, path []string) bool {
+	child, ok := obj[path[0]]
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		return true
+	}
+	childObj, ok := child.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	return hasJSONPath(childObj, path[1:])
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
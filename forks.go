@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+)
+
+// electraForkVersion follows the fork versions in config.yaml. Electra is not
+// part of the BeaconChainConfig of prysm yet, so it is tracked outside of it.
+var electraForkVersion = "0x20000094"
+
+// forkSchedule is the activation epoch of the forks that can be configured.
+// Altair and Bellatrix are always active at genesis since the chain starts post-merge.
+type forkSchedule struct {
+	Capella uint64
+	Deneb   uint64
+	Electra uint64
+}
+
+func defaultForkSchedule() *forkSchedule {
+	return &forkSchedule{
+		Capella: 0,
+		Deneb:   0,
+		Electra: math.MaxUint64,
+	}
+}
+
+// parseForkSchedule applies the '<fork>=<epoch>' overrides to the default schedule
+func parseForkSchedule(overrides []string) (*forkSchedule, error) {
+	schedule := defaultForkSchedule()
+	for _, override := range overrides {
+		name, epochStr, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fork epoch '%s', expected <fork>=<epoch>", override)
+		}
+		epoch, err := strconv.ParseUint(strings.TrimSpace(epochStr), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid epoch in '%s': %v", override, err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "capella":
+			schedule.Capella = epoch
+		case "deneb":
+			schedule.Deneb = epoch
+		case "electra":
+			schedule.Electra = epoch
+		default:
+			return nil, fmt.Errorf("unknown fork '%s', only capella, deneb and electra can be scheduled", name)
+		}
+	}
+	if err := schedule.validate(); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (f *forkSchedule) validate() error {
+	if f.Capella > f.Deneb {
+		return fmt.Errorf("deneb (epoch %d) cannot be activated before capella (epoch %d)", f.Deneb, f.Capella)
+	}
+	if f.Deneb > f.Electra {
+		return fmt.Errorf("electra (epoch %d) cannot be activated before deneb (epoch %d)", f.Electra, f.Deneb)
+	}
+	if f.Electra == 0 {
+		return fmt.Errorf("electra cannot be activated at genesis, the genesis state can only be generated up to deneb")
+	}
+	return nil
+}
+
+// Apply sets the fork epochs in the beacon chain config. The fork schedule of the
+// config is derived from the epochs, so it is initialized again.
+func (f *forkSchedule) Apply(config *params.BeaconChainConfig) {
+	config.AltairForkEpoch = 0
+	config.BellatrixForkEpoch = 0
+	config.CapellaForkEpoch = primitives.Epoch(f.Capella)
+	config.DenebForkEpoch = primitives.Epoch(f.Deneb)
+	config.InitializeForkSchedule()
+}
+
+// GenesisVersion returns the version of the beacon state at genesis,
+// which is the version of the last fork active at epoch 0.
+func (f *forkSchedule) GenesisVersion() int {
+	switch {
+	case f.Deneb == 0:
+		return version.Deneb
+	case f.Capella == 0:
+		return version.Capella
+	default:
+		return version.Bellatrix
+	}
+}
+
+// ApplyEL sets the time based forks of the execution chain config that are
+// not covered by interop.GethTestnetGenesis (Prague).
+func (f *forkSchedule) ApplyEL(gen *core.Genesis, genesisTime uint64) error {
+	if f.Electra == math.MaxUint64 {
+		return nil
+	}
+	startSlot, err := slots.EpochStart(primitives.Epoch(f.Electra))
+	if err != nil {
+		return err
+	}
+	pragueTime := uint64(slots.StartTime(genesisTime, startSlot).Unix())
+	gen.Config.PragueTime = &pragueTime
+	return nil
+}
+
+// CLConfig returns the extra entries of the config.yaml file for the forks that
+// are not part of the BeaconChainConfig
+func (f *forkSchedule) CLConfig() string {
+	if f.Electra == math.MaxUint64 {
+		return ""
+	}
+	return fmt.Sprintf("\nELECTRA_FORK_EPOCH: %d\nELECTRA_FORK_VERSION: %s", f.Electra, electraForkVersion)
+}
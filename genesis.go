@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ecrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+)
+
+var (
+	weiPerEther = big.NewInt(1e18)
+	weiPerGwei  = big.NewInt(1e9)
+)
+
+// parseEther parses a decimal amount of ether (e.g. '32' or '0.5') and returns it in wei
+func parseEther(str string) (*big.Int, error) {
+	amount, ok := new(big.Rat).SetString(str)
+	if !ok || strings.ContainsAny(str, "/eE") {
+		return nil, fmt.Errorf("invalid ether amount '%s'", str)
+	}
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("negative ether amount '%s'", str)
+	}
+	amount.Mul(amount, new(big.Rat).SetInt(weiPerEther))
+	if !amount.IsInt() {
+		return nil, fmt.Errorf("ether amount '%s' has more than 18 decimals", str)
+	}
+	return amount.Num(), nil
+}
+
+// parseValidatorBalances returns the balance in Gwei of each of the numValidators validators.
+// By default every validator has MAX_EFFECTIVE_BALANCE, the overrides have the form
+// '<index>=<ETH>' or '<from>-<to>=<ETH>' (both ends included) and are applied in order.
+func parseValidatorBalances(numValidators uint64, overrides []string) ([]uint64, error) {
+	balances := make([]uint64, numValidators)
+	for i := range balances {
+		balances[i] = params.BeaconConfig().MaxEffectiveBalance
+	}
+
+	for _, override := range overrides {
+		rng, amountStr, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid validator balance '%s', expected <from>-<to>=<ETH>", override)
+		}
+
+		fromStr, toStr, isRange := strings.Cut(rng, "-")
+		from, err := strconv.ParseUint(strings.TrimSpace(fromStr), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator index in '%s': %v", override, err)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.ParseUint(strings.TrimSpace(toStr), 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid validator index in '%s': %v", override, err)
+			}
+		}
+		if from > to {
+			return nil, fmt.Errorf("invalid validator range in '%s'", override)
+		}
+		if to >= numValidators {
+			return nil, fmt.Errorf("validator index %d in '%s' is out of range, there are %d validators", to, override, numValidators)
+		}
+
+		wei, err := parseEther(strings.TrimSpace(amountStr))
+		if err != nil {
+			return nil, err
+		}
+		gwei, rem := new(big.Int).QuoRem(wei, weiPerGwei, new(big.Int))
+		if rem.Sign() != 0 || !gwei.IsUint64() {
+			return nil, fmt.Errorf("invalid validator balance '%s', it must be a whole amount of Gwei", override)
+		}
+		if gwei.Uint64() < params.BeaconConfig().MinDepositAmount {
+			return nil, fmt.Errorf("validator balance in '%s' is below the minimum deposit amount", override)
+		}
+		for i := from; i <= to; i++ {
+			balances[i] = gwei.Uint64()
+		}
+	}
+	return balances, nil
+}
+
+// withdrawalAddresses returns the address of the 0x01 withdrawal credentials of each validator.
+// The address can be a fixed address for all the validators, 'prefunded' to spread the validators
+// across the prefunded accounts or empty to use the address derived from the validator pubkey (as interop does).
+func withdrawalAddresses(pubKeys []common.PublicKey, address string, accounts []*prefundedAccount) ([][20]byte, error) {
+	addrs := make([][20]byte, len(pubKeys))
+	switch {
+	case address == "":
+		for i, pubKey := range pubKeys {
+			addrs[i] = bytesutil.ToBytes20(pubKey.Marshal())
+		}
+	case address == "prefunded":
+		if len(accounts) == 0 {
+			return nil, fmt.Errorf("there are no prefunded accounts to use as withdrawal addresses")
+		}
+		for i := range pubKeys {
+			addrs[i] = ecrypto.PubkeyToAddress(accounts[i%len(accounts)].priv.PublicKey)
+		}
+	case gethcommon.IsHexAddress(address):
+		for i := range pubKeys {
+			addrs[i] = gethcommon.HexToAddress(address)
+		}
+	default:
+		return nil, fmt.Errorf("invalid withdrawal address '%s', expected an address or 'prefunded'", address)
+	}
+	return addrs, nil
+}
+
+// depositDataFromKeys creates the genesis deposits of the validators. It works like
+// interop.DepositDataFromKeysWithExecCreds but with a custom amount and withdrawal address for each deposit.
+func depositDataFromKeys(privKeys []common.SecretKey, pubKeys []common.PublicKey, balances []uint64, withdrawalAddrs [][20]byte) ([]*ethpb.Deposit_Data, [][]byte, error) {
+	domain, err := signing.ComputeDomain(params.BeaconConfig().DomainDeposit, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	depositData := make([]*ethpb.Deposit_Data, len(privKeys))
+	roots := make([][]byte, len(privKeys))
+	for i := range privKeys {
+		// 0x01 withdrawal credentials
+		withdrawalCreds := make([]byte, 12)
+		withdrawalCreds[0] = params.BeaconConfig().ETH1AddressWithdrawalPrefixByte
+		withdrawalCreds = append(withdrawalCreds, withdrawalAddrs[i][:]...)
+
+		msg := &ethpb.DepositMessage{
+			PublicKey:             pubKeys[i].Marshal(),
+			WithdrawalCredentials: withdrawalCreds,
+			Amount:                balances[i],
+		}
+		msgRoot, err := msg.HashTreeRoot()
+		if err != nil {
+			return nil, nil, err
+		}
+		signingRoot, err := (&ethpb.SigningData{ObjectRoot: msgRoot[:], Domain: domain}).HashTreeRoot()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		data := &ethpb.Deposit_Data{
+			PublicKey:             msg.PublicKey,
+			WithdrawalCredentials: msg.WithdrawalCredentials,
+			Amount:                msg.Amount,
+			Signature:             privKeys[i].Sign(signingRoot[:]).Marshal(),
+		}
+		root, err := data.HashTreeRoot()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error computing deposit data root: %v", err)
+		}
+		depositData[i] = data
+		roots[i] = root[:]
+	}
+	return depositData, roots, nil
+}
+
+// readGenesisAlloc reads a JSON file with accounts in the 'alloc' format of the geth genesis.
+// Every account can include its balance, nonce, code and storage.
+func readGenesisAlloc(path string) (types.GenesisAlloc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading genesis alloc: %v", err)
+	}
+	var alloc types.GenesisAlloc
+	if err := json.Unmarshal(data, &alloc); err != nil {
+		return nil, fmt.Errorf("error decoding genesis alloc %s: %v", path, err)
+	}
+	return alloc, nil
+}
+
+// mergeGenesisAlloc adds the accounts of src to dst. It fails if an account is
+// already allocated, to avoid silently replacing the prefunded accounts or the system contracts.
+func mergeGenesisAlloc(dst, src types.GenesisAlloc) error {
+	for addr, account := range src {
+		if _, ok := dst[addr]; ok {
+			return fmt.Errorf("account %s is already allocated in the genesis", addr.Hex())
+		}
+		dst[addr] = account
+	}
+	return nil
+}
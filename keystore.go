@@ -0,0 +1,472 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// pbkdf2 parameters of the EIP-2335 keystores, same as the keystorev4 encryptor
+const (
+	pbkdf2KeyLen = 32
+	pbkdf2PRF    = "hmac-sha256"
+)
+
+// kdfProfiles are the number of pbkdf2 iterations of each KDF profile. The 'fast'
+// profile makes the keystores cheap to generate and to decrypt but it is not
+// secure, it is only meant for local testnets.
+var kdfProfiles = map[string]int{
+	"default": 262144,
+	"fast":    1024,
+}
+
+// keystoreConfig are the options to encrypt the validator keystores
+type keystoreConfig struct {
+	password string
+
+	// kdf is the name of the KDF profile
+	kdf string
+
+	// seed makes the keystores deterministic if set
+	seed string
+}
+
+// Marshal returns the EIP-2335 keystore of the key as json
+func (k *keystoreConfig) Marshal(key common.SecretKey) ([]byte, error) {
+	iterations, ok := kdfProfiles[k.kdf]
+	if !ok {
+		return nil, fmt.Errorf("unknown KDF profile '%s'", k.kdf)
+	}
+	pubKeyHex := hex.EncodeToString(key.PublicKey().Marshal())
+
+	rnd := randomness(k.seed, "keystore/0x"+pubKeyHex)
+	cryptoFields, err := encryptKeystore(key.Marshal(), k.password, iterations, rnd)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newUUID(rnd)
+	if err != nil {
+		return nil, err
+	}
+
+	item := map[string]interface{}{
+		"crypto":      cryptoFields,
+		"uuid":        id,
+		"pubkey":      pubKeyHex, // without 0x in the json file
+		"version":     4,
+		"description": "",
+	}
+	return json.MarshalIndent(item, "", "\t")
+}
+
+// MarshalPrysmAccounts returns the accounts keystore of a prysm wallet with an imported
+// keymanager, a single EIP-2335 keystore that encrypts the list of keys.
+func (k *keystoreConfig) MarshalPrysmAccounts(keys []common.SecretKey) ([]byte, error) {
+	iterations, ok := kdfProfiles[k.kdf]
+	if !ok {
+		return nil, fmt.Errorf("unknown KDF profile '%s'", k.kdf)
+	}
+
+	store := struct {
+		PrivateKeys [][]byte `json:"private_keys"`
+		PublicKeys  [][]byte `json:"public_keys"`
+	}{
+		PrivateKeys: make([][]byte, len(keys)),
+		PublicKeys:  make([][]byte, len(keys)),
+	}
+	for i, key := range keys {
+		store.PrivateKeys[i] = key.Marshal()
+		store.PublicKeys[i] = key.PublicKey().Marshal()
+	}
+	storeJSON, err := json.Marshal(store)
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := randomness(k.seed, "prysm-accounts")
+	cryptoFields, err := encryptKeystore(storeJSON, k.password, iterations, rnd)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newUUID(rnd)
+	if err != nil {
+		return nil, err
+	}
+
+	item := map[string]interface{}{
+		"crypto":  cryptoFields,
+		"uuid":    id,
+		"version": 4,
+		"name":    "keystore",
+	}
+	return json.MarshalIndent(item, "", "\t")
+}
+
+// keystoreDir writes one EIP-2335 keystore per key in the directory layout of a validator client
+type keystoreDir struct {
+	privKeys []common.SecretKey
+	config   *keystoreConfig
+
+	// layout returns the path of the keystore and of the password file of a key. The
+	// layouts with a password shared by all the keystores return an empty secretPath.
+	layout func(pubKeyHex string) (keystorePath string, secretPath string)
+
+	// passwordPath is the file with the shared password, if any
+	passwordPath string
+}
+
+func (k *keystoreDir) Encode(o *output) error {
+	if k.passwordPath != "" {
+		if err := o.WriteFile(k.passwordPath, sensitive(k.config.password)); err != nil {
+			return err
+		}
+	}
+	return parallelForEach(len(k.privKeys), func(i int) error {
+		key := k.privKeys[i]
+		pubKeyHex := "0x" + hex.EncodeToString(key.PublicKey().Marshal())
+
+		valJSON, err := k.config.Marshal(key)
+		if err != nil {
+			return err
+		}
+		keystorePath, secretPath := k.layout(pubKeyHex)
+		files := map[string]interface{}{
+			keystorePath: sensitive(valJSON),
+		}
+		if secretPath != "" {
+			files[secretPath] = sensitive(k.config.password)
+		}
+		return o.WriteBatch(files)
+	})
+}
+
+// lighthouseLayout is the layout of the datadir of the lighthouse validator client
+func lighthouseLayout(pubKeyHex string) (string, string) {
+	return "validators/" + pubKeyHex + "/voting-keystore.json", "secrets/" + pubKeyHex
+}
+
+// tekuLayout is the layout of the --validator-keys=<keys>:<passwords> flag of teku
+func tekuLayout(pubKeyHex string) (string, string) {
+	return "keys/" + pubKeyHex + ".json", "passwords/" + pubKeyHex + ".txt"
+}
+
+// lodestarLayout is the layout of the --keystoresDir and --secretsDir flags of lodestar
+func lodestarLayout(pubKeyHex string) (string, string) {
+	return "keystores/" + pubKeyHex + "/voting-keystore.json", "secrets/" + pubKeyHex
+}
+
+// nimbusLayout is the layout of the --validators-dir and --secrets-dir flags of nimbus
+func nimbusLayout(pubKeyHex string) (string, string) {
+	return "validators/" + pubKeyHex + "/keystore.json", "secrets/" + pubKeyHex
+}
+
+// remoteSignerLayout is the layout of the keys of the remote signer, all of them
+// are encrypted with the same password
+func remoteSignerLayout(pubKeyHex string) (string, string) {
+	return "keys/" + pubKeyHex + ".json", ""
+}
+
+// prysmWallet is a prysm wallet with an imported (local) keymanager, the layout
+// of the --wallet-dir and --wallet-password-file flags of prysm
+type prysmWallet struct {
+	privKeys []common.SecretKey
+	config   *keystoreConfig
+}
+
+func (p *prysmWallet) Encode(o *output) error {
+	accountsJSON, err := p.config.MarshalPrysmAccounts(p.privKeys)
+	if err != nil {
+		return err
+	}
+	return o.WriteBatch(map[string]interface{}{
+		"wallet/direct/accounts/all-accounts.keystore.json": sensitive(accountsJSON),
+		"wallet-password.txt":                               sensitive(p.config.password),
+	})
+}
+
+// newKeystoreDir returns the constructor of a keystoreDir with the given layout
+func newKeystoreDir(layout func(pubKeyHex string) (string, string)) func(privKeys []common.SecretKey, config *keystoreConfig) encObject {
+	return func(privKeys []common.SecretKey, config *keystoreConfig) encObject {
+		return &keystoreDir{privKeys: privKeys, config: config, layout: layout}
+	}
+}
+
+// keystoreLayouts are the validator clients whose key directory layout can be
+// generated with --keystore-format
+var keystoreLayouts = map[string]func(privKeys []common.SecretKey, config *keystoreConfig) encObject{
+	"lighthouse": newKeystoreDir(lighthouseLayout),
+	"prysm": func(privKeys []common.SecretKey, config *keystoreConfig) encObject {
+		return &prysmWallet{privKeys: privKeys, config: config}
+	},
+	"teku":     newKeystoreDir(tekuLayout),
+	"lodestar": newKeystoreDir(lodestarLayout),
+	"nimbus":   newKeystoreDir(nimbusLayout),
+}
+
+func keystoreLayoutNames() []string {
+	names := make([]string, 0, len(keystoreLayouts))
+	for name := range keystoreLayouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// randomPassword generates a keystore password, it is deterministic if the seed is set
+func randomPassword(seed string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(randomness(seed, "password"), buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// parallelForEach runs fn for the indexes [0, n) across a pool of workers,
+// one per CPU. It returns the first error.
+func parallelForEach(n int, fn func(i int) error) error {
+	workers := runtime.NumCPU()
+	if workers > n {
+		workers = n
+	}
+
+	var (
+		next     atomic.Int64
+		firstErr error
+		errOnce  sync.Once
+		wg       sync.WaitGroup
+		failed   atomic.Bool
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if err := fn(i); err != nil {
+					errOnce.Do(func() { firstErr = err })
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// randomness returns the source of the random values used to generate the file
+// identified by label (e.g. the salt of a keystore). If the seed is empty, it
+// is crypto/rand, otherwise it is a deterministic stream derived from the seed and
+// the label so that two runs with the same seed produce the same output.
+func randomness(seed string, label string) io.Reader {
+	if seed == "" {
+		return rand.Reader
+	}
+	return &seededReader{seed: sha256.Sum256([]byte(seed + "/" + label))}
+}
+
+// seededReader is a stream of sha256(seed || counter) blocks
+type seededReader struct {
+	seed    [32]byte
+	counter uint64
+	buf     []byte
+}
+
+func (s *seededReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.buf) == 0 {
+			block := sha256.New()
+			block.Write(s.seed[:])
+			block.Write(binary.BigEndian.AppendUint64(nil, s.counter))
+			s.buf = block.Sum(nil)
+			s.counter++
+		}
+		copied := copy(p[n:], s.buf)
+		s.buf = s.buf[copied:]
+		n += copied
+	}
+	return n, nil
+}
+
+// newUUID generates a uuid with the bytes of rnd
+func newUUID(rnd io.Reader) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(rnd, buf); err != nil {
+		return "", err
+	}
+	return uuid.FormatUUID(buf)
+}
+
+// encryptKeystore encrypts the secret following EIP-2335 with pbkdf2 and aes-128-ctr.
+// It works like the keystorev4 encryptor but the salt and the iv are read from rnd.
+func encryptKeystore(secret []byte, password string, iterations int, rnd io.Reader) (map[string]interface{}, error) {
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rnd, salt); err != nil {
+		return nil, err
+	}
+	iv := make([]byte, 16)
+	if _, err := io.ReadFull(rnd, iv); err != nil {
+		return nil, err
+	}
+
+	decryptionKey := pbkdf2.Key([]byte(normPassword(password)), salt, iterations, pbkdf2KeyLen, sha256.New)
+
+	aesCipher, err := aes.NewCipher(decryptionKey[:16])
+	if err != nil {
+		return nil, err
+	}
+	cipherMsg := make([]byte, len(secret))
+	cipher.NewCTR(aesCipher, iv).XORKeyStream(cipherMsg, secret)
+
+	checksum := sha256.New()
+	checksum.Write(decryptionKey[16:32])
+	checksum.Write(cipherMsg)
+
+	return map[string]interface{}{
+		"kdf": map[string]interface{}{
+			"function": "pbkdf2",
+			"params": map[string]interface{}{
+				"dklen": pbkdf2KeyLen,
+				"c":     iterations,
+				"prf":   pbkdf2PRF,
+				"salt":  hex.EncodeToString(salt),
+			},
+			"message": "",
+		},
+		"checksum": map[string]interface{}{
+			"function": "sha256",
+			"params":   map[string]interface{}{},
+			"message":  hex.EncodeToString(checksum.Sum(nil)),
+		},
+		"cipher": map[string]interface{}{
+			"function": "aes-128-ctr",
+			"params": map[string]interface{}{
+				"iv": hex.EncodeToString(iv),
+			},
+			"message": hex.EncodeToString(cipherMsg),
+		},
+	}, nil
+}
+
+// normPassword normalizes the password as required by EIP-2335: NFKD and
+// without the C0, C1 and Delete control codes.
+func normPassword(password string) string {
+	output := []byte{}
+	for _, r := range norm.NFKD.String(password) {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			continue
+		}
+		output = utf8.AppendRune(output, r)
+	}
+	return string(output)
+}
+
+// keystoreFile is the subset of the EIP-2335 keystore fields required to decrypt it
+type keystoreFile struct {
+	Crypto  map[string]interface{} `json:"crypto"`
+	Pubkey  string                 `json:"pubkey"`
+	Version uint                   `json:"version"`
+}
+
+// readKeystores decrypts all the EIP-2335 keystores (*.json files) in dir, including
+// the subdirectories, with the password in passwordFile. The keys are sorted by file path.
+func readKeystores(dir string, passwordFile string) ([]common.SecretKey, []common.PublicKey, error) {
+	if passwordFile == "" {
+		return nil, nil, fmt.Errorf("a password file is required to decrypt the keystores")
+	}
+	password, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading keystore password: %v", err)
+	}
+
+	paths := []string{}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".json" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading keystores: %v", err)
+	}
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no keystores found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	// decrypting the keystores is slow, do it in parallel
+	encryptor := keystorev4.New()
+	privKeys := make([]common.SecretKey, len(paths))
+	err = parallelForEach(len(paths), func(i int) error {
+		path := paths[i]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var keystore keystoreFile
+		if err := json.Unmarshal(data, &keystore); err != nil {
+			return fmt.Errorf("error decoding keystore %s: %v", path, err)
+		}
+		if keystore.Version != 4 || keystore.Crypto == nil {
+			return fmt.Errorf("%s is not an EIP-2335 keystore", path)
+		}
+
+		secret, err := encryptor.Decrypt(keystore.Crypto, strings.TrimRight(string(password), "\r\n"))
+		if err != nil {
+			return fmt.Errorf("error decrypting keystore %s: %v", path, err)
+		}
+		priv, err := bls.SecretKeyFromBytes(secret)
+		if err != nil {
+			return fmt.Errorf("invalid key in keystore %s: %v", path, err)
+		}
+		if expected, err := hex.DecodeString(strings.TrimPrefix(keystore.Pubkey, "0x")); err != nil || !bytes.Equal(expected, priv.PublicKey().Marshal()) {
+			return fmt.Errorf("the key in keystore %s does not match its pubkey", path)
+		}
+		privKeys[i] = priv
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pubKeys := make([]common.PublicKey, len(paths))
+	found := map[string]string{}
+	for i, priv := range privKeys {
+		pubHex := hex.EncodeToString(priv.PublicKey().Marshal())
+		if other, ok := found[pubHex]; ok {
+			return nil, nil, fmt.Errorf("keystores %s and %s have the same key", other, paths[i])
+		}
+		found[pubHex] = paths[i]
+		pubKeys[i] = priv.PublicKey()
+	}
+	return privKeys, pubKeys, nil
+}
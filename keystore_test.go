@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+)
+
+// testSecretKey is the secret of the EIP-2335 test vectors
+func testSecretKey(t *testing.T) common.SecretKey {
+	t.Helper()
+
+	secret, _ := hex.DecodeString("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f")
+	key, err := bls.SecretKeyFromBytes(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// decryptKeystore decrypts the keystore with the keystorev4 encryptor used by the clients
+func decryptKeystore(t *testing.T, keystore []byte, password string) []byte {
+	t.Helper()
+
+	var file keystoreFile
+	if err := json.Unmarshal(keystore, &file); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := keystorev4.New().Decrypt(file.Crypto, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+func TestKeystoreDecrypt(t *testing.T) {
+	key := testSecretKey(t)
+
+	for kdf := range kdfProfiles {
+		t.Run(kdf, func(t *testing.T) {
+			cfg := &keystoreConfig{password: "password", kdf: kdf}
+			keystore, err := cfg.Marshal(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if secret := decryptKeystore(t, keystore, cfg.password); !bytes.Equal(secret, key.Marshal()) {
+				t.Fatal("decrypted the wrong secret")
+			}
+		})
+	}
+}
+
+func TestKeystorePasswordNormalization(t *testing.T) {
+	key := testSecretKey(t)
+
+	cases := []struct {
+		password string
+
+		// normalized is the same password after the EIP-2335 normalization
+		normalized string
+	}{
+		{"𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑", "testpassword🔑"},
+		{"pass\x7fword\n", "password"},
+	}
+	for _, c := range cases {
+		if normalized := normPassword(c.password); normalized != c.normalized {
+			t.Errorf("expected %q to be normalized to %q but got %q", c.password, c.normalized, normalized)
+		}
+
+		cfg := &keystoreConfig{password: c.password, kdf: "fast"}
+		keystore, err := cfg.Marshal(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, password := range []string{c.password, c.normalized} {
+			if secret := decryptKeystore(t, keystore, password); !bytes.Equal(secret, key.Marshal()) {
+				t.Fatalf("decrypted the wrong secret with password %q", password)
+			}
+		}
+	}
+}
+
+func TestNormPasswordC1(t *testing.T) {
+	// keystorev4 only strips the single byte control codes, so the C1
+	// codes cannot be checked against it
+	if normalized := normPassword("pass\u0085word"); normalized != "password" {
+		t.Fatalf("expected the C1 control codes to be removed but got %q", normalized)
+	}
+}
+
+func TestEncryptKeystoreVector(t *testing.T) {
+	// pbkdf2 test vector of EIP-2335
+	secret, _ := hex.DecodeString("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f")
+	salt, _ := hex.DecodeString("d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3")
+	iv, _ := hex.DecodeString("264daa3f303d7259501c93d997d84fe6")
+
+	fields, err := encryptKeystore(secret, "𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑", 262144, bytes.NewReader(append(salt, iv...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := fields["checksum"].(map[string]interface{})["message"]
+	if checksum != "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1" {
+		t.Fatalf("unexpected checksum %s", checksum)
+	}
+	cipherMsg := fields["cipher"].(map[string]interface{})["message"]
+	if cipherMsg != "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad" {
+		t.Fatalf("unexpected cipher message %s", cipherMsg)
+	}
+}
+
+func TestKeystoreSeed(t *testing.T) {
+	key := testSecretKey(t)
+
+	marshal := func(seed string) []byte {
+		cfg := &keystoreConfig{password: "password", kdf: "fast", seed: seed}
+		keystore, err := cfg.Marshal(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return keystore
+	}
+
+	if !bytes.Equal(marshal("seed"), marshal("seed")) {
+		t.Fatal("the keystores with the same seed are different")
+	}
+	if bytes.Equal(marshal("seed"), marshal("another seed")) {
+		t.Fatal("the keystores with different seeds are equal")
+	}
+	if bytes.Equal(marshal(""), marshal("")) {
+		t.Fatal("the keystores without a seed are equal")
+	}
+
+	password, err := randomPassword("seed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, _ := randomPassword("seed"); other != password {
+		t.Fatal("the passwords with the same seed are different")
+	}
+}
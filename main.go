@@ -2,13 +2,11 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	_ "embed"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html/template"
-	"math/big"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -17,6 +15,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"text/tabwriter"
 	"time"
 
 	"github.com/flashbots/mev-boost-relay/beaconclient"
@@ -27,14 +26,13 @@ import (
 
 	"github.com/ferranbt/suave-playground/artifacts"
 	mevboostrelay "github.com/ferranbt/suave-playground/mev-boost-relay"
+	remotesigner "github.com/ferranbt/suave-playground/remote-signer"
 
-	"github.com/hashicorp/go-uuid"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
 	"github.com/prysmaticlabs/prysm/v5/runtime/interop"
-	"github.com/prysmaticlabs/prysm/v5/runtime/version"
 	"github.com/spf13/cobra"
-	keystorev4 "github.com/wealdtech/go-eth2-wallet-encryptor-keystorev4"
+	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v2"
 )
 
@@ -47,6 +45,42 @@ var outputFlag string
 var resetFlag bool
 var useBinPathFlag bool
 var validateFlag bool
+var artifactsConfigFlag string
+var rethVersionFlag string
+var lighthouseVersionFlag string
+var lockFileFlag string
+var upgradeFlag bool
+var artifactsSourceFlag string
+var cacheDirFlag string
+var verifySignaturesFlag bool
+var signingKeyringFlag string
+var allowUnverifiedFlag bool
+var numValidatorsFlag uint64
+var validatorBalancesFlag []string
+var prefundedMnemonicFlag string
+var prefundedDerivationPathFlag string
+var numPrefundedAccountsFlag uint64
+var prefundedBalanceFlag string
+var genesisAllocFlag []string
+var predeploysFlag []string
+var forkEpochsFlag []string
+var chainConfigFlag string
+var clConfigFlag []string
+var elConfigFlag []string
+var seedFlag string
+var genesisTimeFlag uint64
+var withdrawalAddressFlag string
+var validatorMnemonicFlag string
+var validatorKeystoresFlag string
+var validatorKeystoresPasswordFlag string
+var keystorePasswordFlag string
+var keystoreKDFFlag string
+var keystoreFormatsFlag []string
+var remoteSignerFlag bool
+var remoteSignerDelayFlag time.Duration
+var validatorClientsFlag uint64
+var feeRecipientsFlag []string
+var maxGenesisAgeFlag time.Duration
 
 var rootCmd = &cobra.Command{
 	Use:   "playground",
@@ -62,7 +96,11 @@ var downloadArtifactsCmd = &cobra.Command{
 	Short: "Download the artifacts",
 	Long:  `Download the artifacts`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		bins, err := artifacts.DownloadArtifacts()
+		cfg, err := artifactsConfig()
+		if err != nil {
+			return err
+		}
+		bins, err := artifacts.DownloadArtifacts(cfg)
 		if err != nil {
 			return err
 		}
@@ -81,6 +119,102 @@ var downloadArtifactsCmd = &cobra.Command{
 	},
 }
 
+var bundleOutputFlag string
+
+var artifactsCmd = &cobra.Command{
+	Use:   "artifacts",
+	Short: "Manage the cached artifacts",
+	Long:  `Manage the cached artifacts`,
+}
+
+var artifactsBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Package the cached artifacts into a bundle",
+	Long:  `Package the cached artifacts into a bundle`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := artifactsConfig()
+		if err != nil {
+			return err
+		}
+		if err := artifacts.Bundle(cfg, bundleOutputFlag); err != nil {
+			return err
+		}
+		fmt.Printf("Bundle written to %s\n", bundleOutputFlag)
+		return nil
+	},
+}
+
+var artifactsImportCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Import a bundle into the artifacts cache",
+	Long:  `Import a bundle into the artifacts cache`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := artifactsConfig()
+		if err != nil {
+			return err
+		}
+		return artifacts.Import(cfg, args[0])
+	},
+}
+
+var artifactsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the cached artifacts",
+	Long:  `List the cached artifacts`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := artifactsConfig()
+		if err != nil {
+			return err
+		}
+		bins, err := artifacts.ListCache(cfg)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tSIZE\tSHA256\tIN USE")
+		for _, bin := range bins {
+			inUse := ""
+			if bin.InUse {
+				inUse = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.1f MiB\t%s\t%s\n", bin.Name, bin.Version, float64(bin.Size)/(1<<20), bin.SHA256, inUse)
+		}
+		return w.Flush()
+	},
+}
+
+var artifactsVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the digests of the cached artifacts and that they can run",
+	Long:  `Verify the digests of the cached artifacts and that they can run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := artifactsConfig()
+		if err != nil {
+			return err
+		}
+		return artifacts.VerifyCache(cfg)
+	},
+}
+
+var artifactsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the cached artifacts not referenced by the lockfile",
+	Long:  `Remove the cached artifacts not referenced by the lockfile`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := artifactsConfig()
+		if err != nil {
+			return err
+		}
+		removed, err := artifacts.PruneCache(cfg)
+		for _, path := range removed {
+			fmt.Printf("Removed %s\n", path)
+		}
+		return err
+	},
+}
+
 var numBlocksValidate uint64
 
 var validateCmd = &cobra.Command{
@@ -150,32 +284,152 @@ func main() {
 	rootCmd.Flags().StringVar(&outputFlag, "output", "local-testnet", "")
 	rootCmd.Flags().BoolVar(&resetFlag, "reset", false, "")
 	rootCmd.Flags().BoolVar(&useBinPathFlag, "use-bin-path", false, "")
+	rootCmd.Flags().Uint64Var(&numValidatorsFlag, "num-validators", 100, "")
+	rootCmd.Flags().StringArrayVar(&validatorBalancesFlag, "validator-balance", nil, "")
+	rootCmd.Flags().StringVar(&prefundedMnemonicFlag, "prefunded-mnemonic", defaultMnemonic, "")
+	rootCmd.Flags().StringVar(&prefundedDerivationPathFlag, "prefunded-derivation-path", defaultDerivationPath, "")
+	rootCmd.Flags().Uint64Var(&numPrefundedAccountsFlag, "prefunded-accounts", 10, "")
+	rootCmd.Flags().StringVar(&prefundedBalanceFlag, "prefunded-balance", "100", "")
+	rootCmd.Flags().StringArrayVar(&genesisAllocFlag, "genesis-alloc", nil, "")
+	rootCmd.Flags().StringSliceVar(&predeploysFlag, "predeploy", nil, "")
+	rootCmd.Flags().StringArrayVar(&forkEpochsFlag, "fork-epoch", nil, "")
+	rootCmd.Flags().StringVar(&chainConfigFlag, "chain-config", "", "")
+	rootCmd.Flags().StringArrayVar(&clConfigFlag, "cl-config", nil, "")
+	rootCmd.Flags().StringArrayVar(&elConfigFlag, "el-config", nil, "")
+	rootCmd.Flags().StringVar(&seedFlag, "seed", "", "")
+	rootCmd.Flags().Uint64Var(&genesisTimeFlag, "genesis-time", 0, "")
+	rootCmd.Flags().StringVar(&withdrawalAddressFlag, "withdrawal-address", "", "")
+	rootCmd.Flags().StringVar(&validatorMnemonicFlag, "validator-mnemonic", "", "")
+	rootCmd.Flags().StringVar(&validatorKeystoresFlag, "validator-keystores", "", "")
+	rootCmd.Flags().StringVar(&validatorKeystoresPasswordFlag, "validator-keystores-password", "", "")
+	rootCmd.Flags().StringVar(&keystorePasswordFlag, "keystore-password", "", "")
+	rootCmd.Flags().StringVar(&keystoreKDFFlag, "keystore-kdf", "default", "")
+	rootCmd.Flags().StringSliceVar(&keystoreFormatsFlag, "keystore-format", nil, "")
+	rootCmd.Flags().BoolVar(&remoteSignerFlag, "remote-signer", false, "")
+	rootCmd.Flags().DurationVar(&remoteSignerDelayFlag, "remote-signer-delay", 0, "")
+	rootCmd.Flags().Uint64Var(&validatorClientsFlag, "validator-clients", 1, "")
+	rootCmd.Flags().StringSliceVar(&feeRecipientsFlag, "fee-recipient", nil, "")
+	rootCmd.Flags().DurationVar(&maxGenesisAgeFlag, "max-genesis-age", time.Hour, "")
 	downloadArtifactsCmd.Flags().BoolVar(&validateFlag, "validate", false, "")
 	validateCmd.Flags().Uint64Var(&numBlocksValidate, "num-blocks", 5, "")
+	artifactsBundleCmd.Flags().StringVar(&bundleOutputFlag, "output", "playground-artifacts.tar.gz", "")
+	artifactsImportCmd.Flags().BoolVar(&verifySignaturesFlag, "verify-signatures", false, "")
+
+	// the artifacts subcommands inherit these flags from artifactsCmd
+	for _, flags := range []*pflag.FlagSet{rootCmd.Flags(), downloadArtifactsCmd.Flags(), artifactsCmd.PersistentFlags()} {
+		flags.StringVar(&artifactsConfigFlag, "artifacts-config", "", "")
+		flags.StringVar(&rethVersionFlag, "reth-version", "", "")
+		flags.StringVar(&lighthouseVersionFlag, "lighthouse-version", "", "")
+		flags.StringVar(&lockFileFlag, "lockfile", "playground.lock", "")
+	}
+	rootCmd.PersistentFlags().StringVar(&cacheDirFlag, "cache-dir", "", "")
+	for _, cmd := range []*cobra.Command{rootCmd, downloadArtifactsCmd} {
+		cmd.Flags().BoolVar(&upgradeFlag, "upgrade", false, "")
+		cmd.Flags().StringVar(&artifactsSourceFlag, "artifacts-source", "", "")
+		cmd.Flags().BoolVar(&verifySignaturesFlag, "verify-signatures", false, "")
+		cmd.Flags().StringVar(&signingKeyringFlag, "signing-keyring", "", "")
+		cmd.Flags().BoolVar(&allowUnverifiedFlag, "allow-unverified", false, "")
+	}
 
 	rootCmd.AddCommand(downloadArtifactsCmd)
 	rootCmd.AddCommand(validateCmd)
+
+	artifactsCmd.AddCommand(artifactsBundleCmd)
+	artifactsCmd.AddCommand(artifactsImportCmd)
+	artifactsCmd.AddCommand(artifactsListCmd)
+	artifactsCmd.AddCommand(artifactsVerifyCmd)
+	artifactsCmd.AddCommand(artifactsPruneCmd)
+	rootCmd.AddCommand(artifactsCmd)
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+// artifactsConfig builds the artifacts configuration from the config file and the flags.
+// The flags take precedence over the values in the config file.
+func artifactsConfig() (*artifacts.Config, error) {
+	cfg := &artifacts.Config{}
+	if artifactsConfigFlag != "" {
+		var err error
+		if cfg, err = artifacts.ReadConfig(artifactsConfigFlag); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Versions == nil {
+		cfg.Versions = map[string]string{}
+	}
+	if rethVersionFlag != "" {
+		cfg.Versions["reth"] = rethVersionFlag
+	}
+	if lighthouseVersionFlag != "" {
+		cfg.Versions["lighthouse"] = lighthouseVersionFlag
+	}
+	if artifactsSourceFlag != "" {
+		cfg.Source = artifactsSourceFlag
+	}
+	if cacheDirFlag != "" {
+		cfg.CacheDir = cacheDirFlag
+	}
+	if verifySignaturesFlag {
+		cfg.VerifySignatures = true
+	}
+	if signingKeyringFlag != "" {
+		cfg.SigningKeyring = signingKeyringFlag
+	}
+	if allowUnverifiedFlag {
+		cfg.AllowUnverified = true
+	}
+	cfg.LockFile = lockFileFlag
+	cfg.Upgrade = upgradeFlag
+	return cfg, nil
+}
+
 func runIt() error {
 	out := &output{dst: outputFlag}
 
-	exists := out.Exists("data_reth")
-	if exists && resetFlag || !exists {
-		if resetFlag {
-			if err := out.Remove(""); err != nil {
+	empty, err := isEmptyDir(out.dst)
+	if err != nil {
+		return err
+	}
+	regenerate := resetFlag || empty
+	if !regenerate {
+		switch {
+		case out.Exists(setupMarkerFile):
+			reason, err := staleArtifacts(out, maxGenesisAgeFlag, time.Now())
+			if err != nil {
 				return err
 			}
+			switch {
+			case reason == "":
+				fmt.Println("Artifacts already exist, skipping setup")
+			case !isTerminal():
+				return fmt.Errorf("the artifacts in %s are stale (%s), use --reset to regenerate them", out.dst, reason)
+			default:
+				if regenerate = confirm(fmt.Sprintf("The artifacts in %s are stale (%s). Regenerate them?", out.dst, reason)); !regenerate {
+					fmt.Println("Reusing the stale artifacts")
+				}
+			}
+		case out.Exists(setupStartedFile):
+			// the previous setup failed halfway, there is nothing to reuse
+			fmt.Println("Artifacts are incomplete, regenerating them")
+			regenerate = true
+		default:
+			// the directory was not created by the playground, never delete it without asking
+			question := fmt.Sprintf("%s is not empty and it was not created by the playground. Delete it and generate the artifacts?", out.dst)
+			if !isTerminal() || !confirm(question) {
+				return fmt.Errorf("%s is not empty and it was not created by the playground, use another --output or --reset to delete it", out.dst)
+			}
+			regenerate = true
+		}
+	}
+	if regenerate {
+		if err := out.Remove(""); err != nil {
+			return err
 		}
 		if err := setupArtifacts(); err != nil {
 			return err
 		}
-	} else {
-		fmt.Println("Artifacts already exist, skipping setup")
 	}
 
 	svcManager := newServiceManager(out)
@@ -201,48 +455,133 @@ func runIt() error {
 func setupArtifacts() error {
 	out := &output{dst: outputFlag}
 
+	// mark the directory as a playground output before writing anything else so that
+	// a setup that fails halfway can be told apart from an unrelated directory
+	if err := out.WriteFile(setupStartedFile, []byte{}); err != nil {
+		return err
+	}
+
+	configHash, err := setupConfigHash()
+	if err != nil {
+		return err
+	}
+
 	// load the config.yaml file
 	clConfig, err := params.UnmarshalConfig(clConfigContent, nil)
 	if err != nil {
 		return err
 	}
+	chainConfig, err := readChainConfigOverlay(chainConfigFlag, clConfigFlag, elConfigFlag)
+	if err != nil {
+		return err
+	}
+	if err := chainConfig.ApplyCL(clConfig); err != nil {
+		return err
+	}
+
+	forks, err := parseForkSchedule(forkEpochsFlag)
+	if err != nil {
+		return err
+	}
+	forks.Apply(clConfig)
+
 	if err := params.SetActive(clConfig); err != nil {
 		return err
 	}
 
-	genesisTime := uint64(time.Now().Unix())
+	genesisTime := uint64(time.Now().Unix()) + clConfig.GenesisDelay
+	if genesisTimeFlag != 0 {
+		genesisTime = genesisTimeFlag
+	}
 	config := params.BeaconConfig()
 
 	gen := interop.GethTestnetGenesis(genesisTime, config)
+	if err := chainConfig.ApplyEL(gen); err != nil {
+		return err
+	}
+	if err := forks.ApplyEL(gen, genesisTime); err != nil {
+		return err
+	}
 
 	// add pre-funded accounts
-	prefundedBalance, _ := new(big.Int).SetString("10000000000000000000000", 16)
+	prefundedBalance, err := parseEther(prefundedBalanceFlag)
+	if err != nil {
+		return fmt.Errorf("invalid prefunded balance: %v", err)
+	}
+	accounts, err := deriveAccounts(prefundedMnemonicFlag, prefundedDerivationPathFlag, numPrefundedAccountsFlag)
+	if err != nil {
+		return err
+	}
 
-	for _, privStr := range prefundedAccounts {
-		priv, err := getPrivKey(privStr)
-		if err != nil {
-			return err
-		}
-		addr := ecrypto.PubkeyToAddress(priv.PublicKey)
+	for _, acc := range accounts {
+		addr := ecrypto.PubkeyToAddress(acc.priv.PublicKey)
 		gen.Alloc[addr] = types.Account{
 			Balance: prefundedBalance,
 			Nonce:   1,
 		}
 	}
 
+	// add the predeploys
+	predeployAlloc, err := predeploysAlloc(gen, predeploysFlag)
+	if err != nil {
+		return err
+	}
+	if err := mergeGenesisAlloc(gen.Alloc, predeployAlloc); err != nil {
+		return fmt.Errorf("error merging predeploys: %v", err)
+	}
+
+	// add the custom allocations
+	for _, path := range genesisAllocFlag {
+		alloc, err := readGenesisAlloc(path)
+		if err != nil {
+			return err
+		}
+		if err := mergeGenesisAlloc(gen.Alloc, alloc); err != nil {
+			return fmt.Errorf("error merging genesis alloc %s: %v", path, err)
+		}
+	}
+
 	block := gen.ToBlock()
 
-	v, err := version.FromString("deneb") // TODO: Derive from config.toml
+	if validatorKeystoresFlag != "" && validatorMnemonicFlag != "" {
+		return fmt.Errorf("--validator-keystores and --validator-mnemonic cannot be used together")
+	}
+
+	var (
+		priv []common.SecretKey
+		pub  []common.PublicKey
+	)
+	if validatorKeystoresFlag != "" {
+		priv, pub, err = readKeystores(validatorKeystoresFlag, validatorKeystoresPasswordFlag)
+	} else if validatorMnemonicFlag != "" {
+		priv, pub, err = validatorKeysFromMnemonic(validatorMnemonicFlag, numValidatorsFlag)
+	} else {
+		priv, pub, err = interop.DeterministicallyGenerateKeys(0, numValidatorsFlag)
+	}
+	if err != nil {
+		return err
+	}
+	// with keystores the number of validators is the number of keys
+	numValidators := uint64(len(priv))
+	if numValidators == 0 {
+		return fmt.Errorf("at least one validator is required")
+	}
+	validatorClients, err := splitValidators(numValidators, validatorClientsFlag)
+	if err != nil {
+		return err
+	}
+
+	balances, err := parseValidatorBalances(numValidators, validatorBalancesFlag)
 	if err != nil {
 		return err
 	}
 
-	priv, pub, err := interop.DeterministicallyGenerateKeys(0, 100)
+	withdrawalAddrs, err := withdrawalAddresses(pub, withdrawalAddressFlag, accounts)
 	if err != nil {
 		return err
 	}
 
-	depositData, roots, err := interop.DepositDataFromKeysWithExecCreds(priv, pub, 100)
+	depositData, roots, err := depositDataFromKeys(priv, pub, balances, withdrawalAddrs)
 	if err != nil {
 		return err
 	}
@@ -250,13 +589,31 @@ func setupArtifacts() error {
 	opts := make([]interop.PremineGenesisOpt, 0)
 	opts = append(opts, interop.WithDepositData(depositData, roots))
 
-	state, err := interop.NewPreminedGenesis(context.Background(), genesisTime, 0, 100, v, block, opts...)
+	state, err := interop.NewPreminedGenesis(context.Background(), genesisTime, 0, numValidators, forks.GenesisVersion(), block, opts...)
 	if err != nil {
 		return err
 	}
 
-	err = out.WriteBatch(map[string]interface{}{
-		"testnet/config.yaml":                 func() ([]byte, error) { return convert(config) },
+	// the keystores are encrypted with a random password unless one is given
+	keystoreCfg := &keystoreConfig{password: keystorePasswordFlag, kdf: keystoreKDFFlag, seed: seedFlag}
+	if _, ok := kdfProfiles[keystoreKDFFlag]; !ok {
+		return fmt.Errorf("unknown keystore KDF profile '%s', expected 'default' or 'fast'", keystoreKDFFlag)
+	}
+	if keystoreCfg.password == "" {
+		if keystoreCfg.password, err = randomPassword(seedFlag); err != nil {
+			return err
+		}
+	}
+
+	// the forks that prysm does not know about are appended to the config
+	clConfigRaw, err := convert(config)
+	if err != nil {
+		return err
+	}
+	clConfigRaw = append(clConfigRaw, forks.CLConfig()...)
+
+	files := map[string]interface{}{
+		"testnet/config.yaml":                 clConfigRaw,
 		"testnet/genesis.ssz":                 state,
 		"genesis.json":                        gen,
 		"jwtsecret":                           defaultJWTToken,
@@ -264,26 +621,42 @@ func setupArtifacts() error {
 		"testnet/deploy_block.txt":            "0",
 		"testnet/deposit_contract_block.txt":  "0",
 		"testnet/genesis_validators_root.txt": hex.EncodeToString(state.GenesisValidatorsRoot()),
-		"data_validator/":                     &lighthouseKeystore{privKeys: priv},
-	})
-	if err != nil {
+	}
+	// each validator client gets its own range of the validator keys
+	for i, r := range validatorClients {
+		datadir := validatorDatadir(i, validatorClientsFlag) + "/"
+		if remoteSignerFlag {
+			// the keys are held by the remote signer, the validator client only knows the public keys
+			files[datadir] = &lighthouseRemoteSigner{pubKeys: pub[r.from:r.to], url: remoteSignerURL()}
+		} else {
+			files[datadir] = &keystoreDir{privKeys: priv[r.from:r.to], config: keystoreCfg, layout: lighthouseLayout}
+		}
+	}
+	if remoteSignerFlag {
+		files["data_remote_signer/"] = &keystoreDir{privKeys: priv, config: keystoreCfg, layout: remoteSignerLayout, passwordPath: "password.txt"}
+	}
+	// the lighthouse keystores are always generated for the validator client,
+	// the other layouts are written next to them (e.g. data_validator_teku)
+	for _, format := range keystoreFormatsFlag {
+		layout, ok := keystoreLayouts[format]
+		if !ok {
+			return fmt.Errorf("unknown keystore format '%s', available: %s", format, strings.Join(keystoreLayoutNames(), ", "))
+		}
+		if format != "lighthouse" {
+			files["data_validator_"+format+"/"] = layout(priv, keystoreCfg)
+		}
+	}
+	if err := out.WriteBatch(files); err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func getPrivKey(privStr string) (*ecdsa.PrivateKey, error) {
-	privBuf, err := hex.DecodeString(strings.TrimPrefix(privStr, "0x"))
-	if err != nil {
-		return nil, err
+	// the marker is written last, it is only there if all the artifacts were generated
+	marker := &setupMarker{ConfigHash: configHash, GenesisTime: genesisTime}
+	if err := out.WriteFile(setupMarkerFile, marker); err != nil {
+		return err
 	}
 
-	priv, err := ecrypto.ToECDSA(privBuf)
-	if err != nil {
-		return nil, err
-	}
-	return priv, nil
+	return nil
 }
 
 func setupServices(svcManager *serviceManager, out *output) error {
@@ -297,7 +670,11 @@ func setupServices(svcManager *serviceManager, out *output) error {
 		rethBin = "reth"
 		lighthouseBin = "lighthouse"
 	} else {
-		binArtifacts, err := artifacts.DownloadArtifacts()
+		cfg, err := artifactsConfig()
+		if err != nil {
+			return err
+		}
+		binArtifacts, err := artifacts.DownloadArtifacts(cfg)
 		if err != nil {
 			return err
 		}
@@ -306,11 +683,15 @@ func setupServices(svcManager *serviceManager, out *output) error {
 		lighthouseBin = binArtifacts["lighthouse"]
 	}
 
+	accounts, err := deriveAccounts(prefundedMnemonicFlag, prefundedDerivationPathFlag, numPrefundedAccountsFlag)
+	if err != nil {
+		return err
+	}
+
 	// log the prefunded accounts
 	fmt.Printf("\nPrefunded accounts:\n==================\n")
-	for indx, acc := range prefundedAccounts {
-		priv, _ := getPrivKey(acc)
-		fmt.Printf("(%d) %s (%s)\n", indx, acc, ecrypto.PubkeyToAddress(priv.PublicKey).Hex())
+	for indx, acc := range accounts {
+		fmt.Printf("(%d) %s (%s)\n", indx, acc.PrivKeyHex(), acc.Address())
 	}
 	fmt.Println("")
 
@@ -360,19 +741,61 @@ func setupServices(svcManager *serviceManager, out *output) error {
 		).
 		Run()
 
-	// start validator client
-	svcManager.
-		NewService("validator").
-		WithArgs(
-			lighthouseBin,
-			"vc",
-			"--datadir", "{{.Dir}}/data_validator",
-			"--testnet-dir", "{{.Dir}}/testnet",
-			"--init-slashing-protection",
-			"--beacon-nodes", "http://localhost:3500",
-			"--suggested-fee-recipient", "0x690B9A9E9aa1C9dB991C7721a92d351Db4FaC990",
-			"--builder-proposals",
-		).Run()
+	if remoteSignerFlag {
+		// the remote signer has to be running before the validator client starts
+		dir := filepath.Join(out.dst, "data_remote_signer")
+		keys, _, err := readKeystores(filepath.Join(dir, "keys"), filepath.Join(dir, "password.txt"))
+		if err != nil {
+			return fmt.Errorf("error reading the remote signer keys (use --reset if the artifacts were created without --remote-signer): %v", err)
+		}
+
+		cfg := remotesigner.DefaultConfig()
+		cfg.Keys = keys
+		cfg.Delay = remoteSignerDelayFlag
+		if cfg.LogOutput, err = out.LogOutput("remote-signer"); err != nil {
+			return err
+		}
+		signer, err := remotesigner.New(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create remote signer: %w", err)
+		}
+
+		// like the validator clients, the playground keeps running without the signer
+		go func() {
+			if err := signer.Start(); err != nil {
+				fmt.Printf("Remote signer stopped: %v\n", err)
+			}
+		}()
+	}
+
+	// start the validator clients, each one with its own datadir and fee recipient
+	recipients, err := feeRecipients(validatorClientsFlag, feeRecipientsFlag, accounts)
+	if err != nil {
+		return err
+	}
+	if validatorClientsFlag > 1 {
+		fmt.Printf("Validator clients:\n==================\n")
+		for i, recipient := range recipients {
+			fmt.Printf("(%d) %s (fee recipient %s)\n", i, validatorDatadir(i, validatorClientsFlag), recipient)
+		}
+		fmt.Println("")
+	}
+	for i, recipient := range recipients {
+		svcManager.
+			NewService(validatorServiceName(i, validatorClientsFlag)).
+			WithArgs(
+				lighthouseBin,
+				"vc",
+				"--datadir", "{{.Dir}}/"+validatorDatadir(i, validatorClientsFlag),
+				"--testnet-dir", "{{.Dir}}/testnet",
+				"--init-slashing-protection",
+				"--beacon-nodes", "http://localhost:3500",
+				"--suggested-fee-recipient", recipient,
+				"--builder-proposals",
+			).
+			NonCritical().
+			Run()
+	}
 
 	{
 		cfg := mevboostrelay.DefaultConfig()
@@ -401,7 +824,7 @@ type output struct {
 }
 
 func (o *output) Exists(path string) bool {
-	_, err := os.Stat(filepath.Join(o.dst))
+	_, err := os.Stat(filepath.Join(o.dst, path))
 	return err == nil
 }
 
@@ -437,7 +860,14 @@ func (o *output) WriteFile(dst string, data interface{}) error {
 	var dataRaw []byte
 	var err error
 
-	if raw, ok := data.([]byte); ok {
+	perm := os.FileMode(0644)
+	dirPerm := os.FileMode(0755)
+
+	if raw, ok := data.(sensitive); ok {
+		// only readable by the owner
+		dataRaw = raw
+		perm, dirPerm = 0600, 0700
+	} else if raw, ok := data.([]byte); ok {
 		dataRaw = raw
 	} else if raw, ok := data.(string); ok {
 		dataRaw = []byte(raw)
@@ -471,53 +901,44 @@ func (o *output) WriteFile(dst string, data interface{}) error {
 		}
 	}
 
-	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(dst), dirPerm); err != nil {
 		return err
 	}
-	if err := os.WriteFile(dst, dataRaw, 0644); err != nil {
+	if err := os.WriteFile(dst, dataRaw, perm); err != nil {
 		return err
 	}
 	return nil
 }
 
-var secret = "secret"
+// sensitive is the content of a file that is written with restrictive permissions (e.g. keystores)
+type sensitive []byte
 
-type lighthouseKeystore struct {
-	privKeys []common.SecretKey
+// lighthouseRemoteSigner configures the lighthouse validator client to sign with a Web3Signer
+type lighthouseRemoteSigner struct {
+	pubKeys []common.PublicKey
+	url     string
 }
 
-func (l *lighthouseKeystore) Encode(o *output) error {
-	for _, key := range l.privKeys {
-		encryptor := keystorev4.New()
-		cryptoFields, err := encryptor.Encrypt(key.Marshal(), secret)
-		if err != nil {
-			return err
-		}
-
-		id, _ := uuid.GenerateUUID()
-
-		pubKeyHex := "0x" + hex.EncodeToString(key.PublicKey().Marshal())
-		item := map[string]interface{}{
-			"crypto":      cryptoFields,
-			"uuid":        id,
-			"pubkey":      pubKeyHex[2:], // without 0x in the json file
-			"version":     4,
-			"description": "",
-		}
-		valJSON, err := json.MarshalIndent(item, "", "\t")
-		if err != nil {
-			return err
-		}
-
-		if err := o.WriteBatch(map[string]interface{}{
-			"validators/" + pubKeyHex + "/voting-keystore.json": valJSON,
-			"secrets/" + pubKeyHex:                              secret,
-		}); err != nil {
-			return err
-		}
+func (l *lighthouseRemoteSigner) Encode(o *output) error {
+	definitions := []map[string]interface{}{}
+	for _, pubKey := range l.pubKeys {
+		definitions = append(definitions, map[string]interface{}{
+			"enabled":           true,
+			"voting_public_key": "0x" + hex.EncodeToString(pubKey.Marshal()),
+			"type":              "web3signer",
+			"url":               l.url,
+		})
 	}
+	data, err := yaml.Marshal(definitions)
+	if err != nil {
+		return err
+	}
+	return o.WriteFile("validators/validator_definitions.yml", data)
+}
 
-	return nil
+func remoteSignerURL() string {
+	cfg := remotesigner.DefaultConfig()
+	return fmt.Sprintf("http://%s:%d", cfg.ListenAddr, cfg.ListenPort)
 }
 
 type encObject interface {
@@ -575,7 +996,13 @@ func (s *serviceManager) Run(ss *service) {
 			}
 		}
 		s.wg.Done()
-		s.emitError()
+
+		// only the exit of a critical service stops the playground
+		if ss.critical {
+			s.emitError()
+		} else if !s.stopping.Load() {
+			fmt.Printf("%s exited, the rest of the services keep running\n", ss.name)
+		}
 	}()
 
 	s.handles = append(s.handles, cmd)
@@ -601,11 +1028,14 @@ type service struct {
 	name string
 	args []string
 
+	// critical services stop the playground when they exit
+	critical bool
+
 	srvMng *serviceManager
 }
 
 func (s *serviceManager) NewService(name string) *service {
-	return &service{name: name, args: []string{}, srvMng: s}
+	return &service{name: name, args: []string{}, critical: true, srvMng: s}
 }
 
 func (s *service) WithArgs(args ...string) *service {
@@ -622,6 +1052,12 @@ func (s *service) WithArgs(args ...string) *service {
 	return s
 }
 
+// NonCritical lets the playground keep running if the service exits
+func (s *service) NonCritical() *service {
+	s.critical = false
+	return s
+}
+
 func (s *service) Run() {
 	s.srvMng.Run(s)
 }
@@ -681,16 +1117,3 @@ func isByteArray(t reflect.Type) bool {
 func isByteSlice(t reflect.Type) bool {
 	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
 }
-
-var prefundedAccounts = []string{
-	"0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
-	"0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d",
-	"0x5de4111afa1a4b94908f83103eb1f1706367c2e68ca870fc3fb9a804cdab365a",
-	"0x7c852118294e51e653712a81e05800f419141751be58f605c371e15141b007a6",
-	"0x47e179ec197488593b187f80a00eb0da91f1b9d0b13f8733639f19c30a34926a",
-	"0x8b3a350cf5c34c9194ca85829a2df0ec3153be0318b5e2d3348e872092edffba",
-	"0x92db14e403b83dfe3df233f83dfa3a0d7096f21ca9b0d6d6b8d88b2b4ec1564e",
-	"0x4bbbf85ce3377467afe5d46f804f221813b2bb87f24d81f60f1fcdbf7cbf4356",
-	"0xdbda1821b80551c9d65939329250298aa3472ba22feea921c0cf5d620ea67b97",
-	"0x2a871d0798f97d79848a013d4936a73bf4cc922c825d33c1cf7073dff6d409c6",
-}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	gethparams "github.com/ethereum/go-ethereum/params"
+)
+
+// The bytecode of the predeploys is taken from the compiled contracts in the
+// optimism monorepo (op-e2e/bindings) and from EIP-4788 for the beacon roots contract.
+//
+//go:embed predeploys/*.hex
+var predeploysFS embed.FS
+
+type predeploy struct {
+	address common.Address
+
+	// constructor is set if the bytecode is the creation code of the contract.
+	// Otherwise, it is the runtime code and it is allocated as it is.
+	constructor bool
+
+	// cancun is set if the contract is only meaningful after the Cancun fork
+	cancun bool
+}
+
+var predeploys = map[string]*predeploy{
+	// deterministic deployment proxy used to deploy contracts with CREATE2
+	"create2-deployer": {
+		address:     common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C"),
+		constructor: true,
+	},
+	"multicall3": {
+		address:     common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11"),
+		constructor: true,
+	},
+	"permit2": {
+		address:     common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3"),
+		constructor: true,
+	},
+	// WETH9 at the same address as in mainnet
+	"weth9": {
+		address:     common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"),
+		constructor: true,
+	},
+	// EIP-4788 beacon block roots contract
+	"beacon-roots": {
+		address: gethparams.BeaconRootsStorageAddress,
+		cancun:  true,
+	},
+}
+
+func predeployNames() []string {
+	names := make([]string, 0, len(predeploys))
+	for name := range predeploys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// predeploysAlloc returns the genesis allocation of the predeploys in names.
+// The contracts with a constructor are executed in an in-memory EVM with the
+// chain config of the genesis so that their immutables and storage match a
+// regular deployment at the same address.
+func predeploysAlloc(gen *core.Genesis, names []string) (types.GenesisAlloc, error) {
+	alloc := types.GenesisAlloc{}
+	for _, name := range names {
+		if name == "all" {
+			return predeploysAlloc(gen, predeployNames())
+		}
+		predeploy, ok := predeploys[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown predeploy '%s', available: %s", name, strings.Join(predeployNames(), ", "))
+		}
+		if predeploy.cancun && gen.Config.CancunTime == nil {
+			return nil, fmt.Errorf("predeploy '%s' requires the Cancun fork", name)
+		}
+
+		code, err := readPredeployCode(name)
+		if err != nil {
+			return nil, err
+		}
+		account := types.Account{
+			Code:    code,
+			Balance: new(big.Int),
+			Nonce:   1,
+		}
+		if predeploy.constructor {
+			if account.Code, account.Storage, err = deployPredeploy(gen, predeploy.address, code); err != nil {
+				return nil, fmt.Errorf("error deploying predeploy '%s': %v", name, err)
+			}
+		}
+		alloc[predeploy.address] = account
+	}
+	return alloc, nil
+}
+
+func readPredeployCode(name string) ([]byte, error) {
+	data, err := predeploysFS.ReadFile("predeploys/" + name + ".hex")
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(data)))
+}
+
+// deployPredeploy runs the creation code at addr and returns the runtime code
+// and the storage written by the constructor.
+func deployPredeploy(gen *core.Genesis, addr common.Address, initCode []byte) ([]byte, map[common.Hash]common.Hash, error) {
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the creation code is called as if it was the code of the contract, this way
+	// address(this) in the constructor is the address of the predeploy.
+	statedb.SetCode(addr, initCode)
+
+	tracer := &storageTracer{addr: addr, keys: map[common.Hash]struct{}{}}
+	cfg := &runtime.Config{
+		ChainConfig: gen.Config,
+		Time:        gen.Timestamp,
+		Random:      &common.Hash{},
+		State:       statedb,
+		EVMConfig:   vm.Config{Tracer: tracer},
+	}
+	code, _, err := runtime.Call(addr, nil, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(code) == 0 {
+		return nil, nil, fmt.Errorf("empty runtime code")
+	}
+
+	storage := map[common.Hash]common.Hash{}
+	for key := range tracer.keys {
+		if value := statedb.GetState(addr, key); value != (common.Hash{}) {
+			storage[key] = value
+		}
+	}
+	return code, storage, nil
+}
+
+// storageTracer records the storage slots written by a contract
+type storageTracer struct {
+	addr common.Address
+	keys map[common.Hash]struct{}
+}
+
+func (s *storageTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if op == vm.SSTORE && scope.Contract.Address() == s.addr {
+		s.keys[scope.Stack.Back(0).Bytes32()] = struct{}{}
+	}
+}
+
+func (s *storageTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (s *storageTracer) CaptureTxEnd(restGas uint64) {}
+
+func (s *storageTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+func (s *storageTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (s *storageTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (s *storageTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (s *storageTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
@@ -0,0 +1,216 @@
+package remotesigner
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	ListenAddr string
+	ListenPort uint64
+	Keys       []common.SecretKey
+	LogOutput  io.Writer
+
+	// Delay is the time the signer waits before signing a request
+	Delay time.Duration
+
+	// Refuse makes the signer reject all the signing requests
+	Refuse bool
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		ListenAddr: "127.0.0.1",
+		ListenPort: 9500,
+		LogOutput:  os.Stdout,
+	}
+}
+
+// RemoteSigner is a signing service with the Web3Signer eth2 api. It signs the
+// signing root of the request as it is, without slashing protection, the
+// validator client is expected to keep its own slashing protection database.
+type RemoteSigner struct {
+	log      *logrus.Entry
+	listener net.Listener
+	srv      *http.Server
+
+	keys    map[string]common.SecretKey
+	pubKeys []string
+
+	delay  atomic.Int64
+	refuse atomic.Bool
+}
+
+func New(config *Config) (*RemoteSigner, error) {
+	log := logrus.NewEntry(logrus.New())
+	log.Logger.SetOutput(config.LogOutput)
+
+	r := &RemoteSigner{
+		log:     log,
+		keys:    map[string]common.SecretKey{},
+		pubKeys: []string{},
+	}
+	for _, key := range config.Keys {
+		pubKey := "0x" + hex.EncodeToString(key.PublicKey().Marshal())
+		if _, ok := r.keys[pubKey]; ok {
+			return nil, fmt.Errorf("duplicated key %s", pubKey)
+		}
+		r.keys[pubKey] = key
+		r.pubKeys = append(r.pubKeys, pubKey)
+	}
+	r.SetOutage(config.Refuse, config.Delay)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upcheck", r.handleUpcheck)
+	mux.HandleFunc("/api/v1/eth2/publicKeys", r.handlePublicKeys)
+	mux.HandleFunc("/api/v1/eth2/sign/", r.handleSign)
+	mux.HandleFunc("/playground/outage", r.handleOutage)
+
+	// listen right away so that the validator client can connect as soon as New returns
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.ListenAddr, config.ListenPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	r.listener = listener
+	r.srv = &http.Server{Handler: mux}
+
+	return r, nil
+}
+
+// URL is the address of the api
+func (r *RemoteSigner) URL() string {
+	return "http://" + r.listener.Addr().String()
+}
+
+func (r *RemoteSigner) Start() error {
+	r.log.Infof("Starting remote signer with %d keys at %s", len(r.pubKeys), r.URL())
+	err := r.srv.Serve(r.listener)
+	r.log.WithError(err).Error("Remote signer stopped")
+	return err
+}
+
+// SetOutage changes how the signer handles the signing requests: if refuse is set
+// all the requests fail, otherwise they are signed after the delay.
+func (r *RemoteSigner) SetOutage(refuse bool, delay time.Duration) {
+	r.refuse.Store(refuse)
+	r.delay.Store(int64(delay))
+}
+
+func (r *RemoteSigner) handleUpcheck(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("OK"))
+}
+
+func (r *RemoteSigner) handlePublicKeys(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, r.pubKeys)
+}
+
+// signRequest is the subset of the Web3Signer signing request used by the signer.
+// The type specific fields (e.g. the block or the attestation) are ignored.
+type signRequest struct {
+	Type        string `json:"type"`
+	SigningRoot string `json:"signingRoot"`
+}
+
+func (r *RemoteSigner) handleSign(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pubKey := strings.ToLower(strings.TrimPrefix(req.URL.Path, "/api/v1/eth2/sign/"))
+	key, ok := r.keys[pubKey]
+	if !ok {
+		http.Error(w, "public key not found", http.StatusNotFound)
+		return
+	}
+
+	var signReq signRequest
+	if err := json.NewDecoder(req.Body).Decode(&signReq); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	signingRoot, err := hex.DecodeString(strings.TrimPrefix(signReq.SigningRoot, "0x"))
+	if err != nil || len(signingRoot) != 32 {
+		http.Error(w, "invalid signing root", http.StatusBadRequest)
+		return
+	}
+
+	if r.refuse.Load() {
+		r.log.WithField("type", signReq.Type).Warn("Refusing to sign")
+		http.Error(w, "signer unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if delay := time.Duration(r.delay.Load()); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	signature := "0x" + hex.EncodeToString(key.Sign(signingRoot).Marshal())
+	r.log.WithField("type", signReq.Type).WithField("pubkey", pubKey).Debug("Signed request")
+
+	// Web3Signer returns the signature as text only if it is explicitly requested
+	if accept := req.Header.Get("Accept"); strings.Contains(accept, "text/plain") && !strings.Contains(accept, "application/json") {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(signature))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"signature": signature})
+}
+
+// outage is the body of the /playground/outage endpoint
+type outage struct {
+	Refuse bool   `json:"refuse"`
+	Delay  string `json:"delay"`
+}
+
+// handleOutage returns (GET) or changes (POST) the simulated outage of the signer
+func (r *RemoteSigner) handleOutage(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var o outage
+		if err := json.NewDecoder(req.Body).Decode(&o); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		var delay time.Duration
+		if o.Delay != "" {
+			var err error
+			if delay, err = time.ParseDuration(o.Delay); err != nil || delay < 0 {
+				http.Error(w, fmt.Sprintf("invalid delay '%s'", o.Delay), http.StatusBadRequest)
+				return
+			}
+		}
+		r.SetOutage(o.Refuse, delay)
+		r.log.Infof("Outage updated, refuse: %v, delay: %s", o.Refuse, delay)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, &outage{Refuse: r.refuse.Load(), Delay: time.Duration(r.delay.Load()).String()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// setupStartedFile is written before any other artifact, it identifies the output
+// directories created by the playground
+var setupStartedFile = "setup.started"
+
+// setupMarkerFile is written once all the artifacts are generated, artifacts
+// without it come from a setup that failed halfway
+var setupMarkerFile = "setup.json"
+
+type setupMarker struct {
+	// ConfigHash is the hash of the setupConfig used to generate the artifacts
+	ConfigHash  string `json:"configHash"`
+	GenesisTime uint64 `json:"genesisTime"`
+}
+
+// setupConfig are the inputs of setupArtifacts. The files are identified by the
+// digest of their content so that editing them also changes the hash.
+type setupConfig struct {
+	CLConfig                 string
+	NumValidators            uint64
+	ValidatorBalances        []string
+	PrefundedMnemonic        string
+	PrefundedDerivationPath  string
+	NumPrefundedAccounts     uint64
+	PrefundedBalance         string
+	GenesisAllocs            []string
+	Predeploys               []string
+	ForkEpochs               []string
+	ChainConfig              string
+	CLConfigOverrides        []string
+	ELConfigOverrides        []string
+	Seed                     string
+	GenesisTime              uint64
+	WithdrawalAddress        string
+	ValidatorMnemonic        string
+	ValidatorKeystores       string
+	ValidatorKeystoresPasswd string
+	KeystorePassword         string
+	KeystoreKDF              string
+	KeystoreFormats          []string
+	RemoteSigner             bool
+	ValidatorClients         uint64
+}
+
+// setupConfigHash returns the hash of the flags and files used to generate the artifacts
+func setupConfigHash() (string, error) {
+	genesisAllocs := []string{}
+	for _, path := range genesisAllocFlag {
+		digest, err := fileDigest(path)
+		if err != nil {
+			return "", err
+		}
+		genesisAllocs = append(genesisAllocs, digest)
+	}
+	chainConfig, err := fileDigest(chainConfigFlag)
+	if err != nil {
+		return "", err
+	}
+	keystoresPasswd, err := fileDigest(validatorKeystoresPasswordFlag)
+	if err != nil {
+		return "", err
+	}
+
+	cfg := &setupConfig{
+		CLConfig:                 digest(clConfigContent),
+		NumValidators:            numValidatorsFlag,
+		ValidatorBalances:        validatorBalancesFlag,
+		PrefundedMnemonic:        prefundedMnemonicFlag,
+		PrefundedDerivationPath:  prefundedDerivationPathFlag,
+		NumPrefundedAccounts:     numPrefundedAccountsFlag,
+		PrefundedBalance:         prefundedBalanceFlag,
+		GenesisAllocs:            genesisAllocs,
+		Predeploys:               predeploysFlag,
+		ForkEpochs:               forkEpochsFlag,
+		ChainConfig:              chainConfig,
+		CLConfigOverrides:        clConfigFlag,
+		ELConfigOverrides:        elConfigFlag,
+		Seed:                     seedFlag,
+		GenesisTime:              genesisTimeFlag,
+		WithdrawalAddress:        withdrawalAddressFlag,
+		ValidatorMnemonic:        validatorMnemonicFlag,
+		ValidatorKeystores:       validatorKeystoresFlag,
+		ValidatorKeystoresPasswd: keystoresPasswd,
+		KeystorePassword:         keystorePasswordFlag,
+		KeystoreKDF:              keystoreKDFFlag,
+		KeystoreFormats:          keystoreFormatsFlag,
+		RemoteSigner:             remoteSignerFlag,
+		ValidatorClients:         validatorClientsFlag,
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return digest(data), nil
+}
+
+func digest(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// fileDigest returns the digest of the content of the file or an empty string if path is empty
+func fileDigest(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return digest(data), nil
+}
+
+// staleArtifacts returns why the complete artifacts in the output cannot be reused,
+// or an empty string if they can. The artifacts are stale if they were generated
+// with a different config or if the genesis is older than maxAge (unless the
+// genesis time was set explicitly).
+func staleArtifacts(out *output, maxAge time.Duration, now time.Time) (string, error) {
+	data, err := os.ReadFile(filepath.Join(out.dst, setupMarkerFile))
+	if err != nil {
+		return "", err
+	}
+	var marker setupMarker
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return "", fmt.Errorf("error decoding %s: %v", setupMarkerFile, err)
+	}
+
+	hash, err := setupConfigHash()
+	if err != nil {
+		return "", err
+	}
+	if marker.ConfigHash != hash {
+		return "they were generated with a different config", nil
+	}
+
+	if genesisTimeFlag == 0 && maxAge != 0 {
+		genesis := time.Unix(int64(marker.GenesisTime), 0)
+		if age := now.Sub(genesis); age > maxAge {
+			return fmt.Sprintf("the genesis is %s old", age.Truncate(time.Second)), nil
+		}
+	}
+	return "", nil
+}
+
+// isEmptyDir returns whether the directory does not exist or has no entries
+func isEmptyDir(path string) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return len(entries) == 0, nil
+}
+
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// confirm asks a yes/no question in the terminal
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
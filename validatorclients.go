@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	ecrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// defaultFeeRecipient is the fee recipient of the validators when there is a single validator client
+var defaultFeeRecipient = "0x690B9A9E9aa1C9dB991C7721a92d351Db4FaC990"
+
+// validatorRange is the range [from, to) of the genesis validators of a validator client
+type validatorRange struct {
+	from, to uint64
+}
+
+// splitValidators partitions the validators in numClients contiguous ranges whose
+// sizes differ at most by one
+func splitValidators(numValidators, numClients uint64) ([]validatorRange, error) {
+	if numClients == 0 {
+		return nil, fmt.Errorf("at least one validator client is required")
+	}
+	if numClients > numValidators {
+		return nil, fmt.Errorf("cannot split %d validators across %d validator clients", numValidators, numClients)
+	}
+
+	ranges := make([]validatorRange, numClients)
+	size, rest := numValidators/numClients, numValidators%numClients
+
+	from := uint64(0)
+	for i := range ranges {
+		to := from + size
+		if uint64(i) < rest {
+			to++
+		}
+		ranges[i] = validatorRange{from: from, to: to}
+		from = to
+	}
+	return ranges, nil
+}
+
+// validatorDatadir is the datadir of the i-th validator client. With a single
+// client it is data_validator as before.
+func validatorDatadir(i int, numClients uint64) string {
+	if numClients == 1 {
+		return "data_validator"
+	}
+	return fmt.Sprintf("data_validator_%d", i)
+}
+
+// validatorServiceName is the service name (and log file) of the i-th validator client
+func validatorServiceName(i int, numClients uint64) string {
+	if numClients == 1 {
+		return "validator"
+	}
+	return fmt.Sprintf("validator_%d", i)
+}
+
+// feeRecipients returns the fee recipient of each validator client. If none are
+// given, a single client uses the default fee recipient and multiple clients use
+// the prefunded accounts so that every operator has a different one.
+func feeRecipients(numClients uint64, recipients []string, accounts []*prefundedAccount) ([]string, error) {
+	if len(recipients) != 0 {
+		if uint64(len(recipients)) != numClients {
+			return nil, fmt.Errorf("expected %d fee recipients (one per validator client) but got %d", numClients, len(recipients))
+		}
+		for _, recipient := range recipients {
+			if !gethcommon.IsHexAddress(recipient) {
+				return nil, fmt.Errorf("invalid fee recipient '%s'", recipient)
+			}
+		}
+		return recipients, nil
+	}
+
+	if numClients == 1 {
+		return []string{defaultFeeRecipient}, nil
+	}
+	if uint64(len(accounts)) < numClients {
+		return nil, fmt.Errorf("there are not enough prefunded accounts to use as fee recipients of %d validator clients, use --fee-recipient", numClients)
+	}
+	addrs := make([]string, numClients)
+	for i := range addrs {
+		addrs[i] = ecrypto.PubkeyToAddress(accounts[i].priv.PublicKey).Hex()
+	}
+	return addrs, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/hkdf"
+)
+
+// blsCurveOrder is the order r of the BLS12-381 subgroup
+var blsCurveOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// validatorKeysFromMnemonic derives the signing keys of the first num validators of the
+// mnemonic following EIP-2333 and the EIP-2334 path m/12381/3600/<index>/0/0, the same keys
+// that the staking-deposit-cli generates.
+func validatorKeysFromMnemonic(mnemonic string, num uint64) ([]common.SecretKey, []common.PublicKey, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(strings.Join(strings.Fields(mnemonic), " "), "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid validator mnemonic: %v", err)
+	}
+
+	master, err := deriveMasterSK(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	// m/12381/3600 is shared by all the validators
+	base := master
+	for _, index := range []uint32{12381, 3600} {
+		if base, err = deriveChildSK(base, index); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	privKeys := make([]common.SecretKey, num)
+	pubKeys := make([]common.PublicKey, num)
+	for i := uint64(0); i < num; i++ {
+		sk := base
+		for _, index := range []uint32{uint32(i), 0, 0} {
+			if sk, err = deriveChildSK(sk, index); err != nil {
+				return nil, nil, err
+			}
+		}
+		if privKeys[i], err = bls.SecretKeyFromBytes(sk.FillBytes(make([]byte, 32))); err != nil {
+			return nil, nil, err
+		}
+		pubKeys[i] = privKeys[i].PublicKey()
+	}
+	return privKeys, pubKeys, nil
+}
+
+// deriveMasterSK is derive_master_SK of EIP-2333
+func deriveMasterSK(seed []byte) (*big.Int, error) {
+	if len(seed) < 32 {
+		return nil, fmt.Errorf("seed must be at least 32 bytes")
+	}
+	return hkdfModR(seed, nil)
+}
+
+// deriveChildSK is derive_child_SK of EIP-2333
+func deriveChildSK(parentSK *big.Int, index uint32) (*big.Int, error) {
+	lamportPK, err := parentSKToLamportPK(parentSK, index)
+	if err != nil {
+		return nil, err
+	}
+	return hkdfModR(lamportPK, nil)
+}
+
+func hkdfModR(ikm []byte, keyInfo []byte) (*big.Int, error) {
+	// L = ceil((3 * ceil(log2(r))) / 16) = 48
+	const l = 48
+
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		hash := sha256.Sum256(salt)
+		salt = hash[:]
+
+		prk := hkdf.Extract(sha256.New, append(append([]byte{}, ikm...), 0), salt)
+		info := binary.BigEndian.AppendUint16(append([]byte{}, keyInfo...), l)
+		okm := make([]byte, l)
+		if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), okm); err != nil {
+			return nil, err
+		}
+		sk.SetBytes(okm)
+		sk.Mod(sk, blsCurveOrder)
+	}
+	return sk, nil
+}
+
+func parentSKToLamportPK(parentSK *big.Int, index uint32) ([]byte, error) {
+	salt := binary.BigEndian.AppendUint32(nil, index)
+	ikm := parentSK.FillBytes(make([]byte, 32))
+	notIKM := make([]byte, len(ikm))
+	for i := range ikm {
+		notIKM[i] = ^ikm[i]
+	}
+
+	lamportPK := sha256.New()
+	for _, key := range [][]byte{ikm, notIKM} {
+		lamportSK, err := ikmToLamportSK(key, salt)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range lamportSK {
+			hash := sha256.Sum256(chunk)
+			lamportPK.Write(hash[:])
+		}
+	}
+	return lamportPK.Sum(nil), nil
+}
+
+func ikmToLamportSK(ikm []byte, salt []byte) ([][]byte, error) {
+	okm := make([]byte, 32*255)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, nil), okm); err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 255)
+	for i := range chunks {
+		chunks[i] = okm[i*32 : (i+1)*32]
+	}
+	return chunks, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestEIP2333Vectors(t *testing.T) {
+	// test cases of EIP-2333
+	cases := []struct {
+		seed       string
+		masterSK   string
+		childIndex uint32
+		childSK    string
+	}{
+		{
+			seed:       "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+			masterSK:   "6083874454709270928345386274498605044986640685124978867557563392430687146096",
+			childIndex: 0,
+			childSK:    "20397789859736650942317412262472558107875392172444076792671091975210932703118",
+		},
+		{
+			seed:       "3141592653589793238462643383279502884197169399375105820974944592",
+			masterSK:   "29757020647961307431480504535336562678282505419141012933316116377660817309383",
+			childIndex: 3141592653,
+			childSK:    "25457201688850691947727629385191704516744796114925897962676248250929345014287",
+		},
+		{
+			seed:       "0099FF991111002299DD7744EE3355BBDD8844115566CC55663355668888CC00",
+			masterSK:   "27580842291869792442942448775674722299803720648445448686099262467207037398656",
+			childIndex: 4294967295,
+			childSK:    "29358610794459428860402234341874281240803786294062035874021252734817515685787",
+		},
+		{
+			seed:       "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3",
+			masterSK:   "19022158461524446591288038168518313374041767046816487870552872741050760015818",
+			childIndex: 42,
+			childSK:    "31372231650479070279774297061823572166496564838472787488249775572789064611981",
+		},
+	}
+
+	for i, c := range cases {
+		seed, err := hex.DecodeString(c.seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		master, err := deriveMasterSK(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if master.String() != c.masterSK {
+			t.Fatalf("case %d: expected master SK %s but got %s", i, c.masterSK, master)
+		}
+		child, err := deriveChildSK(master, c.childIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if child.String() != c.childSK {
+			t.Fatalf("case %d: expected child SK %s but got %s", i, c.childSK, child)
+		}
+	}
+}
+
+func TestValidatorKeysFromMnemonicPath(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	privKeys, pubKeys, err := validatorKeysFromMnemonic(mnemonic, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(privKeys) != 2 || len(pubKeys) != 2 {
+		t.Fatalf("expected 2 keys but got %d", len(privKeys))
+	}
+
+	// the signing key of validator i is at m/12381/3600/i/0/0
+	seed := bip39.NewSeed(mnemonic, "")
+	for i := range privKeys {
+		sk, err := deriveMasterSK(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range []uint32{12381, 3600, uint32(i), 0, 0} {
+			if sk, err = deriveChildSK(sk, index); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := new(big.Int).SetBytes(privKeys[i].Marshal()); got.Cmp(sk) != 0 {
+			t.Fatalf("validator %d: expected the key at m/12381/3600/%d/0/0", i, i)
+		}
+		if !pubKeys[i].Equals(privKeys[i].PublicKey()) {
+			t.Fatalf("validator %d: the public key does not match the private key", i)
+		}
+	}
+
+	if _, _, err := validatorKeysFromMnemonic("abandon abandon abandon", 1); err == nil {
+		t.Fatal("expected an error for an invalid mnemonic")
+	}
+}